@@ -0,0 +1,43 @@
+package htmldoc
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// rebaseURL : If u is an absolute URL under one of the base URLs, such as the
+// address the site is published at, return the site path it points to as a
+// root-relative URL, keeping the query and fragment. Also returns the base URL
+// matched. A protocol relative u matches a base of either scheme.
+func rebaseURL(u *url.URL, bases []interface{}) (*url.URL, string, bool) {
+	if u.Host == "" {
+		return nil, "", false
+	}
+	for _, item := range bases {
+		baseStr := fmt.Sprintf("%v", item)
+		base, err := url.Parse(baseStr)
+		if err != nil || base.Host == "" {
+			continue
+		}
+		if u.Scheme != "" && !strings.EqualFold(u.Scheme, base.Scheme) {
+			continue
+		}
+		if !strings.EqualFold(u.Host, base.Host) {
+			continue
+		}
+		basePath := strings.TrimSuffix(base.Path, "/") + "/"
+		sitePath := "/"
+		if strings.HasPrefix(u.Path, basePath) {
+			sitePath += strings.TrimPrefix(u.Path, basePath)
+		} else if u.Path+"/" != basePath {
+			continue
+		}
+		return &url.URL{
+			Path:     sitePath,
+			RawQuery: u.RawQuery,
+			Fragment: u.Fragment,
+		}, baseStr, true
+	}
+	return nil, "", false
+}
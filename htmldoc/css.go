@@ -0,0 +1,156 @@
+package htmldoc
+
+import (
+	"path"
+	"strings"
+)
+
+// CSSURL : A URL found in CSS, from url() or @import
+type CSSURL struct {
+	URL    string // URL with quotes and escapes removed
+	Offset int    // Byte offset of the URL in the CSS
+}
+
+// ExtractCSSURLs : Find the URLs given by url() functions and @import rules in
+// css, skipping comments and other strings. Doesn't attempt to validate the
+// CSS, unterminated comments, strings and functions end the search.
+func ExtractCSSURLs(css string) []CSSURL {
+	urls := make([]CSSURL, 0)
+	for i := 0; i < len(css); {
+		rest := css[i:]
+		switch {
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return urls
+			}
+			i += end + 4
+		case rest[0] == '"' || rest[0] == '\'':
+			_, n, ok := cssString(rest)
+			if !ok {
+				return urls
+			}
+			i += n
+		case hasPrefixFold(rest, "url(") && (i == 0 || !isCSSNameByte(css[i-1])):
+			url, start, n, ok := cssURL(rest)
+			if !ok {
+				return urls
+			}
+			urls = append(urls, CSSURL{URL: url, Offset: i + start})
+			i += n
+		case hasPrefixFold(rest, "@import"):
+			// Either a string or url(), which the next pass picks up
+			i += len("@import")
+			for i < len(css) && isCSSSpace(css[i]) {
+				i++
+			}
+			if i < len(css) && (css[i] == '"' || css[i] == '\'') {
+				url, n, ok := cssString(css[i:])
+				if !ok {
+					return urls
+				}
+				urls = append(urls, CSSURL{URL: url, Offset: i + 1})
+				i += n
+			}
+		default:
+			i++
+		}
+	}
+	return urls
+}
+
+// Read the quoted CSS string at the start of s. Returns its contents without
+// escapes and the number of bytes it takes up, ok is false if it isn't closed.
+func cssString(s string) (string, int, bool) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case quote:
+			return b.String(), i + 1, true
+		case '\\':
+			if i+1 < len(s) && s[i+1] != '\n' {
+				b.WriteByte(s[i+1])
+			}
+			i++
+		case '\n':
+			return "", 0, false
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, false
+}
+
+// Read the url() function at the start of s. Returns the URL, its offset and
+// the number of bytes the function takes up, ok is false if it isn't closed.
+func cssURL(s string) (string, int, int, bool) {
+	i := len("url(")
+	for i < len(s) && isCSSSpace(s[i]) {
+		i++
+	}
+	if i >= len(s) {
+		return "", 0, 0, false
+	}
+
+	var url string
+	start := i
+	if s[i] == '"' || s[i] == '\'' {
+		var n int
+		var ok bool
+		if url, n, ok = cssString(s[i:]); !ok {
+			return "", 0, 0, false
+		}
+		start++
+		i += n
+	} else {
+		// Unquoted, runs up to whitespace or the closing parenthesis
+		var b strings.Builder
+		for ; i < len(s) && s[i] != ')' && !isCSSSpace(s[i]); i++ {
+			if s[i] == '\\' && i+1 < len(s) {
+				i++
+			}
+			b.WriteByte(s[i])
+		}
+		url = b.String()
+	}
+
+	for i < len(s) && isCSSSpace(s[i]) {
+		i++
+	}
+	if i >= len(s) || s[i] != ')' {
+		return "", 0, 0, false
+	}
+	return url, start, i + 1, true
+}
+
+// Does s start with prefix, ignoring ASCII case?
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+func isCSSSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// Could c be part of a CSS identifier? Stops e.g. "my-url(" matching url(
+func isCSSNameByte(c byte) bool {
+	return c == '-' || c == '_' || c >= 0x80 ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// Stylesheet : A Document standing in for the stylesheet at sitePath, so
+// references to URLs in it resolve against its location and issues name it.
+// It isn't parsed as HTML.
+func (dS *DocumentStore) Stylesheet(sitePath string) *Document {
+	sitePath = strings.TrimPrefix(path.Clean("/"+sitePath), "/")
+	doc := &Document{
+		FilePath:   path.Join(dS.BasePath, sitePath),
+		SitePath:   sitePath,
+		BasePath:   path.Dir(sitePath),
+		IgnoreTest: dS.isDirIgnored(path.Dir(sitePath)),
+	}
+	doc.Init()
+	doc.baseURLs = dS.BaseURLs
+	return doc
+}
@@ -0,0 +1,39 @@
+package htmldoc
+
+import (
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+)
+
+func TestExtractCSSURLs(t *testing.T) {
+	css := `@import "a.css";
+@IMPORT url('b.css') screen;
+/* url(commented.png) */
+body { background: URL( c.png ) no-repeat; content: "url(string.png)"; }
+.d { background-image: url("d\".png"), url(e\(1\).png); }
+.f { mask: my-url(f.png); background: url(); }`
+	assert.StringEquals(t, "urls", ExtractCSSURLs(css), []CSSURL{
+		{URL: "a.css", Offset: 9},
+		{URL: "b.css", Offset: 30},
+		{URL: "c.png", Offset: 95},
+		{URL: `d".png`, Offset: 172},
+		{URL: "e(1).png", Offset: 187},
+		{URL: "", Offset: 244},
+	})
+}
+
+func TestExtractCSSURLsUnterminated(t *testing.T) {
+	assert.Equals(t, "comment", len(ExtractCSSURLs("a { b: url(a.png) } /* url(b.png)")), 1)
+	assert.Equals(t, "string", len(ExtractCSSURLs("a { b: url(a.png) } 'url(b.png)")), 1)
+	assert.Equals(t, "function", len(ExtractCSSURLs("a { b: url(a.png) } url(b.png")), 1)
+}
+
+func TestStylesheet(t *testing.T) {
+	dS := NewDocumentStore()
+	dS.BasePath = "fixtures/documents"
+	sheet := dS.Stylesheet("/css/../assets/site.css")
+	assert.Equals(t, "site path", sheet.SitePath, "assets/site.css")
+	assert.Equals(t, "file path", sheet.FilePath, "fixtures/documents/assets/site.css")
+	assert.Equals(t, "base path", sheet.BasePath, "assets")
+}
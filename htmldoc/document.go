@@ -1,8 +1,10 @@
 package htmldoc
 
 import (
+	"bytes"
 	"fmt"
-	"os"
+	"io"
+	"io/ioutil"
 	"path"
 	"sync"
 
@@ -12,23 +14,36 @@ import (
 
 // Document struct, representation of a document within the tested site
 type Document struct {
-	FilePath           string                // Relative to the shell session
-	SitePath           string                // Relative to the site root
-	BasePath           string                // Base for relative links
-	IgnoreTest         bool                  // Ignore this Document for testing.
-	htmlMutex          *sync.Mutex           // Controls access to htmlNode
-	htmlNode           *html.Node            // Parsed output
-	hashMap            map[string]*html.Node // Map of valid id/names of nodes
-	NodesOfInterest    []*html.Node          // Slice of nodes to run checks on
-	State              DocumentState         // Link to a DocumentState struct
-	DoctypeNode        *html.Node            // Pointer to doctype node if exists
-	ignoreTagAttribute string                // Attribute to ignore element and children if found on element
+	FilePath           string                       // Relative to the shell session
+	SitePath           string                       // Relative to the site root
+	BasePath           string                       // Base for relative links
+	IgnoreTest         bool                         // Ignore this Document for testing.
+	htmlMutex          *sync.Mutex                  // Controls access to htmlNode
+	htmlNode           *html.Node                   // Parsed output
+	hashMap            map[string]*html.Node        // Map of valid id/names of nodes
+	NodesOfInterest    []*html.Node                 // Slice of nodes to run checks on
+	State              DocumentState                // Link to a DocumentState struct
+	DoctypeNode        *html.Node                   // Pointer to doctype node if exists
+	nodePositions      map[*html.Node]Position      // Source positions of element and comment nodes
+	ignoreTagAttribute string                       // Attribute to ignore element and children if found on element
+	baseURLs           []interface{}                // URLs the site is published at, references under them are internal
+	styleAttributes    bool                         // Elements with a style attribute are nodes of interest
+	suppressFile       suppression                  // Rules turned off for the whole document
+	suppressRange      suppression                  // Rules turned off at this point of parsing
+	suppressLine       int                          // Line htmltest-disable-next-line applies to
+	suppressNextLine   suppression                  // Rules turned off on suppressLine
+	suppressedNodes    map[*html.Node][]suppression // Rules turned off for nodes of interest
+	contentStarted     bool                         // Parsing has passed the first element of <body>
 }
 
 // DocumentState struct, used by checks that depend on the document being
 // parsed.
 type DocumentState struct {
-	FaviconPresent bool // Have we found a favicon in the document?
+	FaviconPresent bool            // Have we found a favicon in the document?
+	OpenGraph      map[string]bool // OpenGraph properties found in the document
+	Title          string          // Title, compared across documents by CheckDuplicates
+	Description    string          // Meta description, compared across documents by CheckDuplicates
+	Canonical      string          // Resolved canonical URL, compared across documents by CheckDuplicates
 }
 
 // Init : Initialise the Document struct doesn't mesh nice with the NewXYZ()
@@ -39,6 +54,7 @@ func (doc *Document) Init() {
 	doc.htmlMutex = &sync.Mutex{}
 	doc.NodesOfInterest = make([]*html.Node, 0)
 	doc.hashMap = make(map[string]*html.Node)
+	doc.suppressedNodes = make(map[*html.Node][]suppression)
 }
 
 // Parse : Ask Document to parse its HTML file. Returns quickly if this has
@@ -55,18 +71,25 @@ func (doc *Document) Parse() {
 		return
 	}
 
-	// Open, parse, and close document
-	f, err := os.Open(doc.FilePath)
+	// Read and parse document
+	src, err := ioutil.ReadFile(doc.FilePath)
 	output.CheckErrorPanic(err)
-	defer f.Close()
 
-	htmlNode, err := html.Parse(f)
+	htmlNode, err := html.Parse(bytes.NewReader(src))
 	output.CheckErrorGeneric(err)
 
 	doc.htmlNode = htmlNode
+	doc.nodePositions = nodePositions(htmlNode, tagPositions(src))
 	doc.parseNode(htmlNode)
 }
 
+// NodePosition : Where in the source file the element n starts. ok is false
+// if n isn't an element of this document or its position couldn't be found.
+func (doc *Document) NodePosition(n *html.Node) (Position, bool) {
+	position, ok := doc.nodePositions[n]
+	return position, ok
+}
+
 // Internal recursive function that delves into the node tree and captures
 // nodes of interest and node id/names.
 func (doc *Document) parseNode(n *html.Node) {
@@ -80,6 +103,9 @@ func (doc *Document) parseNode(n *html.Node) {
 	case html.DoctypeNode:
 		doc.DoctypeNode = n
 	case html.ElementNode:
+		if n.Parent != nil && n.Parent.Data == "body" {
+			doc.contentStarted = true
+		}
 		// If present save fragment identifier to the hashMap
 		nodeID := GetID(n.Attr)
 		if nodeID != "" {
@@ -87,15 +113,25 @@ func (doc *Document) parseNode(n *html.Node) {
 		}
 		// Identify and store tags of interest
 		switch n.Data {
-		case "a", "area", "audio", "blockquote", "del", "embed", "iframe", "img",
-			"input", "ins", "link", "meta", "object", "q", "script", "source",
-			"track", "video":
+		case "a", "area", "audio", "blockquote", "del", "embed", "html", "iframe",
+			"img", "input", "ins", "link", "meta", "object", "q", "script",
+			"source", "style", "title", "track", "video":
 			// Nodes of interest
 			doc.NodesOfInterest = append(doc.NodesOfInterest, n)
+			doc.suppressNode(n)
 		case "base":
 			// Set BasePath from <base> tag
 			doc.BasePath = path.Join(doc.BasePath, GetAttr(n.Attr, "href"))
+		default:
+			// Any element may have URLs in its style attribute
+			if doc.styleAttributes && AttrPresent(n.Attr, "style") {
+				doc.NodesOfInterest = append(doc.NodesOfInterest, n)
+				doc.suppressNode(n)
+			}
 		}
+	case html.CommentNode:
+		// May turn rules off or on for following nodes
+		doc.parseComment(n)
 	case html.ErrorNode:
 		fmt.Printf("%+v\n", n)
 		fmt.Println("Oops, in parsing your HTML we fell over.\n",
@@ -115,3 +151,27 @@ func (doc *Document) IsHashValid(hash string) bool {
 	_, ok := doc.hashMap[hash]
 	return ok
 }
+
+// ExtractHashes : Parse HTML from r and return the id/names of its nodes, the
+// same set of fragment identifiers a Document stores in its hashMap. Used to
+// check hashes on pages we don't hold in the DocumentStore.
+func ExtractHashes(r io.Reader) ([]string, error) {
+	htmlNode, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, 0)
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if nodeID := GetID(n.Attr); nodeID != "" {
+				hashes = append(hashes, nodeID)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(htmlNode)
+	return hashes, nil
+}
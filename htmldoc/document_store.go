@@ -20,6 +20,8 @@ type DocumentStore struct {
 	DocumentExtension  string               // File extension to look for
 	DirectoryIndex     string               // What file is the index of the directory
 	IgnoreTagAttribute string               // Attribute to ignore element and children if found on element
+	BaseURLs           []interface{}        // URLs the site is published at, references under them are internal
+	StyleAttributes    bool                 // Elements with a style attribute are nodes of interest
 }
 
 // NewDocumentStore : Create and return a new Document store.
@@ -37,6 +39,8 @@ func (dS *DocumentStore) AddDocument(doc *Document) {
 	dS.DocumentPathMap[doc.SitePath] = doc
 	// Pass some vars on
 	doc.ignoreTagAttribute = dS.IgnoreTagAttribute
+	doc.baseURLs = dS.BaseURLs
+	doc.styleAttributes = dS.StyleAttributes
 }
 
 // Discover : Discover all documents within DocumentStore.BasePath.
@@ -1,6 +1,7 @@
 package htmldoc
 
 import (
+	"os"
 	"sync"
 	"testing"
 
@@ -60,7 +61,7 @@ func TestDocumentNodesOfInterest(t *testing.T) {
 	}
 	doc.Init()
 	doc.Parse()
-	assert.Equals(t, "nodes of interest", len(doc.NodesOfInterest), 12)
+	assert.Equals(t, "nodes of interest", len(doc.NodesOfInterest), 13)
 }
 
 func TestDocumentBasePathDefault(t *testing.T) {
@@ -93,3 +94,14 @@ func TestDocumentIsHashValid(t *testing.T) {
 	assert.IsTrue(t, "#prq present", doc.IsHashValid("prq"))
 	assert.IsFalse(t, "#abc present", doc.IsHashValid("abc"))
 }
+
+func TestExtractHashes(t *testing.T) {
+	// extract the same id/names from a reader as a parsed document holds
+	f, err := os.Open("fixtures/documents/index.html")
+	assert.Equals(t, "open error", err, nil)
+	defer f.Close()
+
+	hashes, err := ExtractHashes(f)
+	assert.Equals(t, "extract error", err, nil)
+	assert.StringEquals(t, "hashes", hashes, []string{"xyz", "prq"})
+}
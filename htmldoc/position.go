@@ -0,0 +1,112 @@
+package htmldoc
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// Position : Where an element starts in its source file
+type Position struct {
+	Line   int // 1-based line number
+	Column int // 1-based column, in characters
+	Offset int // 0-based byte offset
+}
+
+// Keys in the positions map for comments, and the text of <style> elements
+const (
+	commentKey   string = "#comment"
+	styleTextKey string = "#style"
+)
+
+// Within : Position of the byte offset in text, which starts at p
+func (p Position) Within(text string, offset int) Position {
+	before := text[:offset]
+	p.Offset += offset
+	if i := strings.LastIndexByte(before, '\n'); i >= 0 {
+		p.Line += strings.Count(before, "\n")
+		p.Column = 1
+		before = before[i+1:]
+	}
+	p.Column += utf8.RuneCountInString(before)
+	return p
+}
+
+// tagPositions : Tokenize src and return the position of every start tag,
+// grouped by tag name in source order, of every comment under commentKey and
+// of the text of every <style> element under styleTextKey. html.Parse doesn't
+// give positions so these are matched up to the parsed nodes by nodePositions.
+func tagPositions(src []byte) map[string][]Position {
+	positions := make(map[string][]Position)
+	z := html.NewTokenizer(bytes.NewReader(src))
+
+	line, column, offset := 1, 1, 0
+	inStyle := false
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// End of src, the tokenizer copes with any malformed HTML
+			return positions
+		}
+		raw := z.Raw()
+
+		wasStyle := inStyle
+		inStyle = false
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			name, _ := z.TagName()
+			positions[string(name)] = append(positions[string(name)], Position{line, column, offset})
+			inStyle = tt == html.StartTagToken && string(name) == "style"
+		} else if tt == html.CommentToken {
+			positions[commentKey] = append(positions[commentKey], Position{line, column, offset})
+		} else if tt == html.TextToken && wasStyle {
+			positions[styleTextKey] = append(positions[styleTextKey], Position{line, column, offset})
+		}
+
+		// Advance our position past the raw token
+		for len(raw) > 0 {
+			r, size := utf8.DecodeRune(raw)
+			if r == '\n' {
+				line++
+				column = 1
+			} else {
+				column++
+			}
+			offset += size
+			raw = raw[size:]
+		}
+	}
+}
+
+// nodePositions : Match the element, comment and <style> text nodes under n to
+// the positions found by tagPositions. Nodes are paired with tags of the same
+// name, comments or style text, in document order. Elements the parser adds
+// itself, such as an implied <tbody>, may be mismatched but none of those are
+// checked.
+func nodePositions(n *html.Node, positions map[string][]Position) map[*html.Node]Position {
+	nodes := make(map[*html.Node]Position)
+	seen := make(map[string]int)
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		key := n.Data
+		isStyleText := n.Type == html.TextNode && n.Parent != nil && n.Parent.Data == "style"
+		if n.Type == html.CommentNode {
+			key = commentKey
+		} else if isStyleText {
+			key = styleTextKey
+		}
+		if n.Type == html.ElementNode || n.Type == html.CommentNode || isStyleText {
+			i := seen[key]
+			if i < len(positions[key]) {
+				nodes[n] = positions[key][i]
+			}
+			seen[key] = i + 1
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return nodes
+}
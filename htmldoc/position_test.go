@@ -0,0 +1,49 @@
+package htmldoc
+
+import (
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+)
+
+func TestDocumentNodePosition(t *testing.T) {
+	// records where nodes of interest start in the source
+	doc := Document{
+		FilePath:           "fixtures/positions.html",
+		ignoreTagAttribute: "data-proofer-ignore",
+	}
+	doc.Init()
+	doc.Parse()
+
+	positions := make([]Position, 0)
+	for _, n := range doc.NodesOfInterest {
+		position, ok := doc.NodePosition(n)
+		assert.IsTrue(t, n.Data+" position found", ok)
+		positions = append(positions, position)
+	}
+	assert.StringEquals(t, "positions", positions, []Position{
+		{Line: 2, Column: 1, Offset: 16},    // html
+		{Line: 4, Column: 3, Offset: 32},    // script
+		{Line: 8, Column: 14, Offset: 161},  // a, after multi-byte characters
+		{Line: 10, Column: 18, Offset: 277}, // a, after the ignored one
+		{Line: 12, Column: 3, Offset: 332},  // img, after the one in noscript
+	})
+}
+
+func TestReferencePosition(t *testing.T) {
+	// references take the position of their node
+	doc := Document{FilePath: "fixtures/positions.html"}
+	doc.Init()
+	doc.Parse()
+	n := doc.NodesOfInterest[len(doc.NodesOfInterest)-1]
+	ref, err := NewReference(&doc, n, "img.png")
+	assert.NoErrorf(t, "NewReference", err)
+	assert.Equals(t, "line", ref.Line, 12)
+	assert.Equals(t, "column", ref.Column, 3)
+}
+
+func TestPositionWithin(t *testing.T) {
+	start := Position{Line: 3, Column: 10, Offset: 40}
+	assert.Equals(t, "same line", start.Within("a { b: url(é.png) }", 11), Position{3, 21, 51})
+	assert.Equals(t, "later line", start.Within("a {\n  b: url(x.png)\n}", 12), Position{4, 9, 52})
+}
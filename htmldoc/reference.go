@@ -15,10 +15,15 @@ type Reference struct {
 	Node     *html.Node // Node reference was created from
 	Path     string     // href/src taken verbatim from source
 	URL      *url.URL   // URL object created from Path
+	Attr     string     // Attribute Path was taken from, empty if not from an attribute
+	Line     int        // Line of Node in the document's source, 0 if unknown
+	Column   int        // Column of Node in the document's source, 0 if unknown
+	BaseURL  string     // Base URL Path is under, URL is then the site path it points to
 }
 
 // NewReference : Create a new reference given a document, node and path.
-// Generates the URL object.
+// Generates the URL object. Absolute URLs under one of the document's base URLs
+// are given the site path they point to as their URL.
 func NewReference(document *Document, node *html.Node, path string) (*Reference, error) {
 
 	// Clean path
@@ -36,13 +41,23 @@ func NewReference(document *Document, node *html.Node, path string) (*Reference,
 		return nil, err
 	}
 	ref.URL = u
+	if document != nil {
+		if siteURL, base, ok := rebaseURL(u, document.baseURLs); ok {
+			ref.URL = siteURL
+			ref.BaseURL = base
+		}
+		if position, ok := document.NodePosition(node); ok {
+			ref.Line = position.Line
+			ref.Column = position.Column
+		}
+	}
 	return &ref, nil
 }
 
 // Scheme : Returns the scheme of the reference. Uses URL.Scheme and adds
 // "file" and "self" schemes for inter-file and intra-file references.
 func (ref *Reference) Scheme() string {
-	if strings.HasPrefix(ref.Path, "//") {
+	if ref.BaseURL == "" && strings.HasPrefix(ref.Path, "//") {
 		// Could be http or https, we can handle https so prefer that
 		// TODO Should we test both?
 		return "https"
@@ -71,14 +86,18 @@ func (ref *Reference) Scheme() string {
 func (ref *Reference) URLString() string {
 	// Format url for use in http.Get
 	urlStr := ref.URL.String()
-	if strings.HasPrefix(ref.Path, "//") {
+	if ref.BaseURL == "" && strings.HasPrefix(ref.Path, "//") {
 		return "https:" + ref.URL.String()
 	}
 	return urlStr
 }
 
-// IsInternalAbsolute : Is an internal absolute link.
+// IsInternalAbsolute : Is an internal absolute link, including absolute URLs
+// under a base URL.
 func (ref *Reference) IsInternalAbsolute() bool {
+	if ref.BaseURL != "" {
+		return true
+	}
 	return !strings.HasPrefix(ref.Path, "//") && strings.HasPrefix(ref.Path, "/")
 }
 
@@ -96,3 +115,9 @@ func (ref *Reference) RefSitePath() string {
 func URLStripQueryString(urlStr string) string {
 	return strings.Split(urlStr, "?")[0]
 }
+
+// URLStripFragment : Utility function to remove the fragment (hash) from given
+// urlStr.
+func URLStripFragment(urlStr string) string {
+	return strings.Split(urlStr, "#")[0]
+}
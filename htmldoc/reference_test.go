@@ -122,6 +122,43 @@ func TestReferenceAbsolutePath(t *testing.T) {
 	assert.Equals(t, "internal relative reference", ref.RefSitePath(), "directory/subdir/zzy/uup.jjr")
 }
 
+func TestReferenceBaseURL(t *testing.T) {
+	snip := "<img src=\"x\" alt=\"y\" />"
+	nodeDoc, nodeElem := nodeGen(snip)
+
+	doc := Document{
+		SitePath: "doc.html",
+		BasePath: "directory",
+		htmlNode: nodeDoc,
+		baseURLs: []interface{}{"https://www.example.com/", "http://example.org/docs"},
+	}
+
+	var ref *Reference
+
+	ref, _ = NewReference(&doc, nodeElem, "https://www.example.com/docs/page/?a=1#intro")
+	assert.Equals(t, "base URL scheme", ref.Scheme(), "file")
+	assert.Equals(t, "base URL site path", ref.RefSitePath(), "/docs/page/")
+	assert.Equals(t, "base URL string", ref.URLString(), "/docs/page/?a=1#intro")
+	assert.Equals(t, "base URL matched", ref.BaseURL, "https://www.example.com/")
+	ref, _ = NewReference(&doc, nodeElem, "https://WWW.example.com")
+	assert.Equals(t, "base URL root", ref.URLString(), "/")
+	assert.IsTrue(t, "base URL internal absolute", ref.IsInternalAbsolute())
+	ref, _ = NewReference(&doc, nodeElem, "//www.example.com/img.png")
+	assert.Equals(t, "protocol relative", ref.URLString(), "/img.png")
+	ref, _ = NewReference(&doc, nodeElem, "http://example.org/docs/guide.html")
+	assert.Equals(t, "base URL path", ref.URLString(), "/guide.html")
+	ref, _ = NewReference(&doc, nodeElem, "http://example.org/docs")
+	assert.Equals(t, "base URL path without slash", ref.URLString(), "/")
+
+	ref, _ = NewReference(&doc, nodeElem, "http://www.example.com/page/")
+	assert.Equals(t, "other scheme", ref.Scheme(), "http")
+	ref, _ = NewReference(&doc, nodeElem, "http://example.org/docsets/")
+	assert.Equals(t, "outside base path", ref.Scheme(), "http")
+	ref, _ = NewReference(&doc, nodeElem, "https://example.com/page/")
+	assert.Equals(t, "other host", ref.Scheme(), "https")
+	assert.Equals(t, "other host base URL", ref.BaseURL, "")
+}
+
 func TestURLStripQueryString(t *testing.T) {
 	original := "https://github.com/wjdp/gotdict/issues/new?title=Harwood Fell&body=[_definitions/harwood-fell.mdd](https://github.com/wjdp/gotdict/blob/master/_definitions/harwood-fell.mdd)"
 	actual := URLStripQueryString(original)
@@ -129,3 +166,10 @@ func TestURLStripQueryString(t *testing.T) {
 
 	assert.Equals(t, "stripped url", actual, expected)
 }
+
+func TestURLStripFragment(t *testing.T) {
+	assert.Equals(t, "stripped url", URLStripFragment("https://example.com/page.html#heading"),
+		"https://example.com/page.html")
+	assert.Equals(t, "unchanged url", URLStripFragment("https://example.com/page.html"),
+		"https://example.com/page.html")
+}
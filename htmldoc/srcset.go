@@ -0,0 +1,133 @@
+package htmldoc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SrcsetCandidate : An image candidate from a srcset or imagesrcset attribute
+type SrcsetCandidate struct {
+	URL     string  // URL of the image, verbatim
+	Width   int     // Width descriptor, e.g. 400 for "400w", 0 if not given
+	Height  int     // Height descriptor, only allowed with a width, 0 if not given
+	Density float64 // Pixel density descriptor, e.g. 2 for "2x", 0 if not given
+	Invalid string  // Why the descriptors are malformed, empty if they're valid
+}
+
+// ParseSrcset : Split a srcset attribute into its image candidates, following
+// the HTML parsing algorithm. URLs are runs of non-whitespace, so may contain
+// commas as data URIs do, candidates are separated by commas after a URL or
+// its descriptors.
+func ParseSrcset(srcset string) []SrcsetCandidate {
+	candidates := make([]SrcsetCandidate, 0)
+	s := srcset
+	for {
+		// Skip whitespace and commas before the URL
+		s = strings.TrimLeftFunc(s, func(r rune) bool { return unicode.IsSpace(r) || r == ',' })
+		if s == "" {
+			return candidates
+		}
+
+		end := strings.IndexFunc(s, unicode.IsSpace)
+		if end < 0 {
+			end = len(s)
+		}
+		candidate := SrcsetCandidate{URL: s[:end]}
+		s = s[end:]
+
+		if strings.HasSuffix(candidate.URL, ",") {
+			// A comma straight after the URL ends the candidate, no descriptors
+			candidate.URL = strings.TrimRight(candidate.URL, ",")
+		} else {
+			var descriptors string
+			descriptors, s = splitDescriptors(s)
+			candidate.parseDescriptors(strings.Fields(descriptors))
+		}
+		candidates = append(candidates, candidate)
+	}
+}
+
+// Split s at the first comma outside parentheses, returning the descriptors
+// before it and the rest of the srcset after it.
+func splitDescriptors(s string) (string, string) {
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				return s[:i], s[i+1:]
+			}
+		}
+	}
+	return s, ""
+}
+
+// Set the candidate's width, height and density from its descriptors, or
+// Invalid if they're malformed.
+func (candidate *SrcsetCandidate) parseDescriptors(descriptors []string) {
+	for _, descriptor := range descriptors {
+		value, unit := descriptor[:len(descriptor)-1], descriptor[len(descriptor)-1]
+		var err error
+		switch unit {
+		case 'w':
+			if candidate.Width != 0 || candidate.Density != 0 {
+				err = fmt.Errorf("more than one width or density descriptor")
+			} else if candidate.Width, err = positiveInt(value); err != nil {
+				err = fmt.Errorf("invalid width descriptor %q", descriptor)
+			}
+		case 'h':
+			if candidate.Height != 0 || candidate.Density != 0 {
+				err = fmt.Errorf("more than one height or density descriptor")
+			} else if candidate.Height, err = positiveInt(value); err != nil {
+				err = fmt.Errorf("invalid height descriptor %q", descriptor)
+			}
+		case 'x':
+			if candidate.Width != 0 || candidate.Height != 0 || candidate.Density != 0 {
+				err = fmt.Errorf("more than one width or density descriptor")
+			} else if candidate.Density, err = density(value); err != nil {
+				err = fmt.Errorf("invalid density descriptor %q", descriptor)
+			}
+		default:
+			err = fmt.Errorf("unknown descriptor %q", descriptor)
+		}
+		if err != nil {
+			candidate.Invalid = err.Error()
+			return
+		}
+	}
+	if candidate.Height != 0 && candidate.Width == 0 {
+		candidate.Invalid = "height descriptor without a width descriptor"
+	}
+}
+
+// Parse a width or height descriptor's value, digits only and above zero
+func positiveInt(value string) (int, error) {
+	if value == "" || strings.TrimLeft(value, "0123456789") != "" {
+		return 0, fmt.Errorf("not a number")
+	}
+	n, err := strconv.Atoi(value)
+	if err == nil && n <= 0 {
+		err = fmt.Errorf("not above zero")
+	}
+	return n, err
+}
+
+// Parse a density descriptor's value, a floating point number above zero
+func density(value string) (float64, error) {
+	if value == "" || strings.TrimLeft(value, "0123456789.eE+-") != "" || strings.HasPrefix(value, "+") {
+		return 0, fmt.Errorf("not a number")
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err == nil && f <= 0 {
+		err = fmt.Errorf("not above zero")
+	}
+	return f, err
+}
@@ -0,0 +1,36 @@
+package htmldoc
+
+import (
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+)
+
+func TestParseSrcset(t *testing.T) {
+	candidates := ParseSrcset(" a.png 100w 50h,b.png  2.5x ,\n c.png,d.png,, data:image/png;base64,AA== 1x")
+	assert.StringEquals(t, "candidates", candidates, []SrcsetCandidate{
+		{URL: "a.png", Width: 100, Height: 50},
+		{URL: "b.png", Density: 2.5},
+		{URL: "c.png,d.png"}, // Commas only separate candidates after whitespace
+		{URL: "data:image/png;base64,AA==", Density: 1},
+	})
+	assert.Equals(t, "empty", len(ParseSrcset(" ,, ")), 0)
+}
+
+func TestParseSrcsetInvalid(t *testing.T) {
+	invalid := func(srcset string) string {
+		candidates := ParseSrcset(srcset)
+		assert.Equals(t, srcset+" candidates", len(candidates), 1)
+		return candidates[0].Invalid
+	}
+	assert.Equals(t, "negative width", invalid("a.png -100w"), `invalid width descriptor "-100w"`)
+	assert.Equals(t, "zero width", invalid("a.png 0w"), `invalid width descriptor "0w"`)
+	assert.Equals(t, "fractional width", invalid("a.png 1.5w"), `invalid width descriptor "1.5w"`)
+	assert.Equals(t, "zero density", invalid("a.png 0x"), `invalid density descriptor "0x"`)
+	assert.Equals(t, "signed density", invalid("a.png +2x"), `invalid density descriptor "+2x"`)
+	assert.Equals(t, "no unit", invalid("a.png 2"), `unknown descriptor "2"`)
+	assert.Equals(t, "two densities", invalid("a.png 1x 2x"), "more than one width or density descriptor")
+	assert.Equals(t, "width and density", invalid("a.png 100w 2x"), "more than one width or density descriptor")
+	assert.Equals(t, "height alone", invalid("a.png 50h"), "height descriptor without a width descriptor")
+	assert.Equals(t, "parenthesised", invalid("a.png (future) 100w"), `unknown descriptor "(future)"`)
+}
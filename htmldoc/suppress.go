@@ -0,0 +1,157 @@
+package htmldoc
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Comment directives turning off the issues of some, or all, rules
+const (
+	directiveDisable         string = "htmltest-disable"
+	directiveEnable          string = "htmltest-enable"
+	directiveDisableNextLine string = "htmltest-disable-next-line"
+	directiveDisableFile     string = "htmltest-disable-file"
+)
+
+// suppression : Rules turned off by comments. Rules are given as codes, e.g.
+// img/alt-missing, or categories, e.g. img. Treated as immutable, with and
+// without return changed copies, so nodes can share them.
+type suppression struct {
+	all    bool            // Every rule is off, except those in except
+	rules  map[string]bool // Rules which are off when all isn't set
+	except map[string]bool // Rules which are on when all is set
+}
+
+// Does the rule code match one of the given codes or categories?
+func ruleListed(rule string, list map[string]bool) bool {
+	if list[rule] {
+		return true
+	}
+	for i, c := range rule {
+		if c == '/' && list[rule[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// Copy of list with rules added, or removed
+func ruleSet(list map[string]bool, rules []string, on bool) map[string]bool {
+	set := make(map[string]bool, len(list)+len(rules))
+	for rule := range list {
+		set[rule] = true
+	}
+	for _, rule := range rules {
+		if on {
+			set[rule] = true
+		} else {
+			delete(set, rule)
+		}
+	}
+	return set
+}
+
+// Does the suppression turn off the given rule?
+func (s suppression) covers(rule string) bool {
+	if s.all {
+		return !ruleListed(rule, s.except)
+	}
+	return ruleListed(rule, s.rules)
+}
+
+// Is anything turned off?
+func (s suppression) active() bool {
+	return s.all || len(s.rules) > 0
+}
+
+// Suppression with the given rules also turned off, all of them if none are
+// given
+func (s suppression) with(rules []string) suppression {
+	if len(rules) == 0 {
+		return suppression{all: true}
+	}
+	if s.all {
+		return suppression{all: true, except: ruleSet(s.except, rules, false)}
+	}
+	return suppression{rules: ruleSet(s.rules, rules, true)}
+}
+
+// Suppression with the given rules turned back on, all of them if none are
+// given
+func (s suppression) without(rules []string) suppression {
+	if len(rules) == 0 {
+		return suppression{}
+	}
+	if s.all {
+		return suppression{all: true, except: ruleSet(s.except, rules, true)}
+	}
+	return suppression{rules: ruleSet(s.rules, rules, false)}
+}
+
+// parseDirective : The directive and rules of an htmltest comment, e.g.
+// "htmltest-disable img/alt-missing link". ok is false for other comments.
+func parseDirective(comment string) (directive string, rules []string, ok bool) {
+	fields := strings.Fields(comment)
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+	switch fields[0] {
+	case directiveDisable, directiveEnable, directiveDisableNextLine, directiveDisableFile:
+		return fields[0], fields[1:], true
+	}
+	return "", nil, false
+}
+
+// parseComment : Update the document's suppressions for a comment node
+func (doc *Document) parseComment(n *html.Node) {
+	directive, rules, ok := parseDirective(n.Data)
+	if !ok {
+		return
+	}
+	switch directive {
+	case directiveDisable:
+		doc.suppressRange = doc.suppressRange.with(rules)
+	case directiveEnable:
+		doc.suppressRange = doc.suppressRange.without(rules)
+	case directiveDisableFile:
+		// Only at the top of the document, before any content
+		if !doc.contentStarted {
+			doc.suppressFile = doc.suppressFile.with(rules)
+		}
+	case directiveDisableNextLine:
+		if position, ok := doc.nodePositions[n]; ok {
+			doc.suppressLine = position.Line + 1
+			doc.suppressNextLine = suppression{}.with(rules)
+		}
+	}
+}
+
+// suppressNode : Record the suppressions in force at element n
+func (doc *Document) suppressNode(n *html.Node) {
+	active := make([]suppression, 0, 2)
+	if doc.suppressRange.active() {
+		active = append(active, doc.suppressRange)
+	}
+	if position, ok := doc.nodePositions[n]; ok && position.Line == doc.suppressLine {
+		active = append(active, doc.suppressNextLine)
+	}
+	if len(active) > 0 {
+		doc.suppressedNodes[n] = active
+	}
+}
+
+// Suppressed : Has a comment in the document turned off the rule for the
+// element n? With a nil n only htmltest-disable-file comments apply, for
+// issues with the document as a whole.
+func (doc *Document) Suppressed(n *html.Node, rule string) bool {
+	if doc.suppressFile.covers(rule) {
+		return true
+	}
+	for _, s := range doc.suppressedNodes[n] {
+		if s.covers(rule) {
+			return true
+		}
+	}
+	return false
+}
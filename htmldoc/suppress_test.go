@@ -0,0 +1,47 @@
+package htmldoc
+
+import (
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+)
+
+func TestDocumentSuppressed(t *testing.T) {
+	// comments turn rules off for following nodes, lines or the whole file
+	doc := Document{FilePath: "fixtures/suppress.html"}
+	doc.Init()
+	doc.Parse()
+
+	nodes := make(map[string]bool)
+	for _, n := range doc.NodesOfInterest {
+		src := GetAttr(n.Attr, "src") + GetAttr(n.Attr, "href")
+		nodes[src] = doc.Suppressed(n, "img/alt-missing") || doc.Suppressed(n, "link/target-missing")
+	}
+	assert.Equals(t, "one.png", nodes["one.png"], false)
+	assert.Equals(t, "two.png next line", nodes["two.png"], true)
+	assert.Equals(t, "three.png same line", nodes["three.png"], true)
+	assert.Equals(t, "four.png", nodes["four.png"], false)
+	assert.Equals(t, "five.html in range", nodes["five.html"], true)
+	assert.Equals(t, "seven.png by category", nodes["seven.png"], true)
+	assert.Equals(t, "eight.html after range", nodes["eight.html"], false)
+
+	six := doc.NodesOfInterest[6] // After <html>
+	assert.Equals(t, "six.html", GetAttr(six.Attr, "href"), "six.html")
+	assert.IsFalse(t, "six.html rule enabled", doc.Suppressed(six, "link/target-missing"))
+	assert.IsTrue(t, "six.html other rules", doc.Suppressed(six, "link/hash-missing"))
+
+	assert.IsTrue(t, "file", doc.Suppressed(nil, "doctype/missing"))
+	assert.IsFalse(t, "file other rules", doc.Suppressed(nil, "favicon/missing"))
+	assert.IsFalse(t, "file after content", doc.Suppressed(nil, "img/alt-missing"))
+}
+
+func TestParseDirective(t *testing.T) {
+	directive, rules, ok := parseDirective(" htmltest-disable img/alt-missing  link ")
+	assert.IsTrue(t, "ok", ok)
+	assert.Equals(t, "directive", directive, directiveDisable)
+	assert.StringEquals(t, "rules", rules, []string{"img/alt-missing", "link"})
+	_, _, ok = parseDirective(" htmltest-disabled ")
+	assert.IsFalse(t, "unknown directive", ok)
+	_, _, ok = parseDirective(" a comment ")
+	assert.IsFalse(t, "other comment", ok)
+}
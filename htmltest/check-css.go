@@ -0,0 +1,118 @@
+package htmltest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/issues"
+	"golang.org/x/net/html"
+)
+
+// stylesheetSet : Local stylesheets linked from the documents, each is only
+// scanned once however many documents link it.
+type stylesheetSet struct {
+	mutex  *sync.Mutex
+	byPath map[string]*htmldoc.Document
+	sheets []*htmldoc.Document // In the order first linked
+}
+
+func newStylesheetSet() *stylesheetSet {
+	return &stylesheetSet{
+		mutex:  &sync.Mutex{},
+		byPath: make(map[string]*htmldoc.Document),
+	}
+}
+
+// add : The stylesheet at sitePath, first is true if it hasn't been seen
+// before and so needs scanning. Thread safe.
+func (sS *stylesheetSet) add(dS *htmldoc.DocumentStore, sitePath string) (sheet *htmldoc.Document, first bool) {
+	sheet = dS.Stylesheet(sitePath)
+	sS.mutex.Lock()
+	defer sS.mutex.Unlock()
+	if seen, ok := sS.byPath[sheet.SitePath]; ok {
+		return seen, false
+	}
+	sS.byPath[sheet.SitePath] = sheet
+	sS.sheets = append(sS.sheets, sheet)
+	return sheet, true
+}
+
+// Checks the URLs in the provided node's style attribute
+func (hT *HTMLTest) checkStyleAttr(document *htmldoc.Document, node *html.Node) {
+	// Positions within attributes aren't known, refs are given the element's
+	hT.checkCSS(document, node, htmldoc.GetAttr(node.Attr, "style"), "style", nil)
+}
+
+// Checks the URLs in the provided <style> element
+func (hT *HTMLTest) checkStyleElement(document *htmldoc.Document, node *html.Node) {
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.TextNode {
+			continue
+		}
+		var start *htmldoc.Position
+		if position, ok := document.NodePosition(c); ok {
+			start = &position
+		}
+		hT.checkCSS(document, node, c.Data, "", start)
+	}
+}
+
+// Checks the URLs in the local stylesheet ref links to, once per run. URLs are
+// relative to the stylesheet, and issues are given for it rather than the
+// document linking it.
+func (hT *HTMLTest) checkStylesheet(ref *htmldoc.Reference) {
+	sheet, first := hT.stylesheets.add(&hT.documentStore, ref.RefSitePath())
+	if !first || sheet.IgnoreTest {
+		return
+	}
+
+	// A missing stylesheet has already been reported by checkInternal
+	src, err := ioutil.ReadFile(sheet.FilePath)
+	if err != nil {
+		return
+	}
+
+	hT.issueStore.AddIssue(issues.Issue{
+		Level:   issues.LevelDebug,
+		Message: "checking stylesheet " + sheet.SitePath,
+	})
+	hT.checkCSS(sheet, nil, string(src), "", &htmldoc.Position{Line: 1, Column: 1})
+}
+
+// Checks the url() and @import URLs in css, found in the provided document
+// and node. If start is given it's where css begins in the document, and refs
+// are given the position of their URL.
+func (hT *HTMLTest) checkCSS(document *htmldoc.Document, node *html.Node, css string, attr string,
+	start *htmldoc.Position) {
+	for _, cssURL := range htmldoc.ExtractCSSURLs(css) {
+		ref, err := htmldoc.NewReference(document, node, cssURL.URL)
+		if err != nil {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:    issues.LevelError,
+				Document: document,
+				Message:  fmt.Sprintf("bad reference: %q", err),
+				Rule:     issues.RuleRefInvalid,
+			})
+			continue
+		}
+		ref.Attr = attr
+		if start != nil {
+			position := start.Within(css, cssURL.Offset)
+			ref.Line, ref.Column = position.Line, position.Column
+		}
+
+		if cssURL.URL == "" {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:     issues.LevelError,
+				Message:   "CSS url is blank",
+				Rule:      issues.RuleRefBlank,
+				Reference: ref,
+			})
+			continue
+		}
+
+		hT.checkGenericRef(ref)
+	}
+}
@@ -0,0 +1,56 @@
+package htmltest
+
+import (
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+	"github.com/wjdp/htmltest/issues"
+)
+
+func TestCSSStyleAttr(t *testing.T) {
+	// checks url() in style attributes
+	hT := tTestFileOpts("fixtures/css/styleAttr.html",
+		map[string]interface{}{"CheckCSS": true})
+	tExpectIssueCount(t, hT, 2)
+	tExpectIssue(t, hT, "target does not exist", 1)
+	tExpectIssue(t, hT, "CSS url is blank", 1)
+}
+
+func TestCSSStyleElement(t *testing.T) {
+	// checks url() and @import in <style> elements, at their own position
+	hT := tTestFileOpts("fixtures/css/styleElement.html",
+		map[string]interface{}{"CheckCSS": true})
+	tExpectIssueCount(t, hT, 2)
+	errors := hT.issueStore.Issues(issues.LevelError)
+	assert.Equals(t, "import path", errors[0].Reference.Path, "css/missing.css")
+	assert.Equals(t, "import line", errors[0].Reference.Line, 5)
+	assert.Equals(t, "import column", errors[0].Reference.Column, 14)
+	assert.Equals(t, "url path", errors[1].Reference.Path, "images/nope.png")
+	assert.Equals(t, "url line", errors[1].Reference.Line, 8)
+	assert.Equals(t, "url column", errors[1].Reference.Column, 31)
+}
+
+func TestCSSStylesheet(t *testing.T) {
+	// scans linked stylesheets once, resolving URLs against the stylesheet
+	hT := tTestFileOpts("fixtures/css/stylesheet.html",
+		map[string]interface{}{"CheckCSS": true})
+	tExpectIssueCount(t, hT, 2)
+	tExpectIssue(t, hT, "checking stylesheet css/site.css", 1)
+	for _, issue := range hT.issueStore.Issues(issues.LevelError) {
+		assert.Equals(t, "document", issue.Reference.Document.SitePath, "css/site.css")
+	}
+	errors := hT.issueStore.Issues(issues.LevelError)
+	assert.Equals(t, "import path", errors[0].Reference.Path, "print.css")
+	assert.Equals(t, "import line", errors[0].Reference.Line, 1)
+	assert.Equals(t, "url path", errors[1].Reference.Path, "../images/gone.jpg")
+	assert.Equals(t, "url line", errors[1].Reference.Line, 12)
+	assert.Equals(t, "url column", errors[1].Reference.Column, 20)
+}
+
+func TestCSSDisabled(t *testing.T) {
+	// CSS isn't checked by default
+	for _, fixture := range []string{"styleAttr.html", "styleElement.html", "stylesheet.html"} {
+		hT := tTestFile("fixtures/css/" + fixture)
+		tExpectIssueCount(t, hT, 0)
+	}
+}
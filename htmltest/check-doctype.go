@@ -15,6 +15,7 @@ func (hT *HTMLTest) checkDoctype(document *htmldoc.Document) {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:    issues.LevelError,
 			Message:  "missing doctype",
+			Rule:     issues.RuleDoctypeMissing,
 			Document: document,
 		})
 		return
@@ -35,6 +36,7 @@ func (hT *HTMLTest) checkDoctype(document *htmldoc.Document) {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:    issues.LevelError,
 			Message:  "doctype isn't html5",
+			Rule:     issues.RuleDoctypeNotHTML5,
 			Document: document,
 		})
 	}
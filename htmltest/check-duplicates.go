@@ -0,0 +1,93 @@
+package htmltest
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/issues"
+)
+
+// Values compared across documents by checkDuplicates, in the order reported
+var duplicateChecks = []struct {
+	rule  string
+	name  string
+	value func(state *htmldoc.DocumentState) string
+}{
+	{issues.RuleDuplicateTitle, "title", func(s *htmldoc.DocumentState) string { return s.Title }},
+	{issues.RuleDuplicateDescription, "meta description", func(s *htmldoc.DocumentState) string { return s.Description }},
+	{issues.RuleDuplicateCanonical, "canonical URL", func(s *htmldoc.DocumentState) string { return s.Canonical }},
+}
+
+// Save the values of the document compared by checkDuplicates into its state,
+// after it's been parsed. Values whose rule is turned off by a comment in the
+// document are left out.
+func (hT *HTMLTest) recordDuplicates(document *htmldoc.Document) {
+	tags := findHeadTags(document)
+
+	if len(tags.titles) > 0 && !document.Suppressed(tags.titles[0], issues.RuleDuplicateTitle) {
+		document.State.Title = strings.Join(strings.Fields(nodeText(tags.titles[0])), " ")
+	}
+	if len(tags.descriptions) > 0 && !document.Suppressed(tags.descriptions[0], issues.RuleDuplicateDescription) {
+		content := htmldoc.GetAttr(tags.descriptions[0].Attr, "content")
+		document.State.Description = strings.Join(strings.Fields(content), " ")
+	}
+	if len(tags.canonicals) > 0 && !document.Suppressed(tags.canonicals[0], issues.RuleDuplicateCanonical) {
+		n := tags.canonicals[0]
+		ref, err := htmldoc.NewReference(document, n, htmldoc.GetAttr(n.Attr, "href"))
+		if err != nil {
+			// Reported by checkLink
+			return
+		}
+		// Compare where canonical links point, not how they're written
+		switch ref.Scheme() {
+		case "file":
+			document.State.Canonical = path.Clean("/" + ref.RefSitePath())
+		case "http", "https":
+			document.State.Canonical = htmldoc.URLStripFragment(ref.URLString())
+		}
+	}
+}
+
+// Checks for titles, meta descriptions and canonical URLs shared by more than
+// one document, once every document has been tested. Each document sharing a
+// value has an issue naming the others.
+func (hT *HTMLTest) checkDuplicates() {
+	for _, check := range duplicateChecks {
+		values := make([]string, 0)
+		documents := make(map[string][]*htmldoc.Document)
+		for _, document := range hT.documentStore.Documents {
+			value := check.value(&document.State)
+			if document.IgnoreTest || value == "" {
+				continue
+			}
+			if _, ok := documents[value]; !ok {
+				values = append(values, value)
+			}
+			documents[value] = append(documents[value], document)
+		}
+
+		for _, value := range values {
+			shared := documents[value]
+			if len(shared) < 2 {
+				continue
+			}
+			for _, document := range shared {
+				others := make([]string, 0, len(shared)-1)
+				for _, other := range shared {
+					if other != document {
+						others = append(others, other.SitePath)
+					}
+				}
+				hT.issueStore.AddIssue(issues.Issue{
+					Level:    issues.LevelError,
+					Document: document,
+					Message: fmt.Sprintf("%s %q shared with %s",
+						check.name, value, strings.Join(others, ", ")),
+					Rule: check.rule,
+				})
+			}
+		}
+	}
+}
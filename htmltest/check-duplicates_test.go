@@ -0,0 +1,51 @@
+package htmltest
+
+import (
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+
+	"github.com/wjdp/htmltest/issues"
+)
+
+func TestDuplicatesDisabled(t *testing.T) {
+	// doesn't compare documents by default
+	hT := tTestDirectoryOpts("fixtures/duplicates", map[string]interface{}{
+		"CheckExternal": false,
+	})
+	tExpectIssueCount(t, hT, 0)
+}
+
+func TestDuplicates(t *testing.T) {
+	// reports each document sharing a value, naming the others
+	hT := tTestDirectoryOpts("fixtures/duplicates", map[string]interface{}{
+		"CheckExternal":   false,
+		"CheckDuplicates": true,
+	})
+	tExpectIssueCount(t, hT, 10)
+	tExpectIssue(t, hT, `title "My Site" shared with`, 4)
+	tExpectIssue(t, hT, `title "My Site" shared with contact.html, blog/draft.html, index.html`, 1)
+	tExpectIssue(t, hT, `meta description "All about me and my site." shared with`, 2)
+	tExpectIssue(t, hT, `canonical URL "https://www.example.com/" shared with`, 2)
+	tExpectIssue(t, hT, `canonical URL "/about.html" shared with blog/index.html`, 1)
+	tExpectIssue(t, hT, `canonical URL "/about.html" shared with about.html`, 1)
+	about, _ := hT.documentStore.ResolvePath("about.html")
+	assert.Equals(t, "about.html", hT.issueStore.CountByDoc(issues.LevelError, about), 3)
+	for _, issue := range hT.issueStore.Issues(issues.LevelError) {
+		if issue.Document == nil {
+			t.Error("duplicate issue without a document", issue.Message)
+		}
+	}
+}
+
+func TestDuplicatesConcurrent(t *testing.T) {
+	// compares documents once they've all been tested
+	for _, mode := range []string{"TestFilesConcurrently", "TestFilesPipeline"} {
+		hT := tTestDirectoryOpts("fixtures/duplicates", map[string]interface{}{
+			"CheckExternal":   false,
+			"CheckDuplicates": true,
+			mode:              true,
+		})
+		tExpectIssueCount(t, hT, 10)
+	}
+}
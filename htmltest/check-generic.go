@@ -22,15 +22,18 @@ func (hT *HTMLTest) checkGeneric(document *htmldoc.Document, node *html.Node, ke
 			Level:    issues.LevelError,
 			Document: document,
 			Message:  fmt.Sprintf("bad reference: %q", err),
+			Rule:     issues.RuleRefInvalid,
 		})
 		return
 	}
+	ref.Attr = key
 
 	// Check attr isn't blank
 	if urlStr == "" {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelError,
 			Message:   fmt.Sprintf(node.Data, key, "is blank"),
+			Rule:      issues.RuleRefBlank,
 			Reference: ref,
 		})
 	}
@@ -59,15 +62,12 @@ func (hT *HTMLTest) enforceHTTPS(ref *htmldoc.Reference) {
 	if hT.opts.isURLIgnored(urlStr) || hT.opts.isInsecureURLIgnored(urlStr) {
 		return
 	}
-	issueLevel := issues.LevelError
-	if hT.opts.IgnoreExternalBrokenLinks {
-		issueLevel = issues.LevelWarning
-	}
 
 	if hT.opts.EnforceHTTPS {
 		hT.issueStore.AddIssue(issues.Issue{
-			Level:     issueLevel,
+			Level:     issues.LevelError,
 			Message:   "is not an HTTPS target",
+			Rule:      issues.RuleRefNotHTTPS,
 			Reference: ref,
 		})
 	}
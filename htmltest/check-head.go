@@ -0,0 +1,142 @@
+package htmltest
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/issues"
+	"golang.org/x/net/html"
+)
+
+// How far into a document browsers look for the character encoding
+const charsetPrescanBytes int = 1024
+
+// headTags : Elements of a document's head which checks look at
+type headTags struct {
+	html         []*html.Node
+	titles       []*html.Node
+	descriptions []*html.Node
+	canonicals   []*html.Node
+	charsets     []*html.Node
+}
+
+// Find the head tags amongst the document's nodes of interest, in document
+// order.
+func findHeadTags(document *htmldoc.Document) headTags {
+	var tags headTags
+	for _, n := range document.NodesOfInterest {
+		switch n.Data {
+		case "html":
+			tags.html = append(tags.html, n)
+		case "title":
+			// SVG has <title> elements of its own
+			if n.Namespace == "" {
+				tags.titles = append(tags.titles, n)
+			}
+		case "meta":
+			if strings.EqualFold(htmldoc.GetAttr(n.Attr, "name"), "description") {
+				tags.descriptions = append(tags.descriptions, n)
+			}
+			if htmldoc.AttrPresent(n.Attr, "charset") ||
+				(strings.EqualFold(htmldoc.GetAttr(n.Attr, "http-equiv"), "content-type") &&
+					strings.Contains(strings.ToLower(htmldoc.GetAttr(n.Attr, "content")), "charset=")) {
+				tags.charsets = append(tags.charsets, n)
+			}
+		case "link":
+			if hasRel(n, "canonical") {
+				tags.canonicals = append(tags.canonicals, n)
+			}
+		}
+	}
+	return tags
+}
+
+// Checks the document has the recommended tags in its head, after it's been
+// parsed. Issues with a particular element are given its position.
+func (hT *HTMLTest) checkHead(document *htmldoc.Document) {
+	tags := findHeadTags(document)
+	titles, descriptions, canonicals, charsets := tags.titles, tags.descriptions, tags.canonicals, tags.charsets
+
+	for _, n := range tags.html {
+		if strings.TrimSpace(htmldoc.GetAttr(n.Attr, "lang")) == "" {
+			hT.headIssue(document, n, "<html> missing lang attribute", issues.RuleHeadLangMissing)
+		}
+	}
+
+	// Exactly one non-empty <title>
+	if len(titles) == 0 {
+		hT.headIssue(document, nil, "<title> missing", issues.RuleHeadTitleMissing)
+	}
+	for i, n := range titles {
+		if i > 0 {
+			hT.headIssue(document, n, "more than one <title>", issues.RuleHeadTitleMultiple)
+		} else if strings.TrimSpace(nodeText(n)) == "" {
+			hT.headIssue(document, n, "<title> empty", issues.RuleHeadTitleEmpty)
+		}
+	}
+
+	// A meta description of a sensible length
+	if len(descriptions) == 0 {
+		hT.headIssue(document, nil, "meta description missing", issues.RuleHeadDescriptionMissing)
+	} else {
+		n := descriptions[0]
+		length := utf8.RuneCountInString(strings.TrimSpace(htmldoc.GetAttr(n.Attr, "content")))
+		if length < hT.opts.HeadDescriptionMinLength || length > hT.opts.HeadDescriptionMaxLength {
+			hT.headIssue(document, n, fmt.Sprintf("meta description is %d characters, should be %d to %d",
+				length, hT.opts.HeadDescriptionMinLength, hT.opts.HeadDescriptionMaxLength),
+				issues.RuleHeadDescriptionLength)
+		}
+	}
+
+	// At most one canonical link
+	for i, n := range canonicals {
+		if i > 0 {
+			hT.headIssue(document, n, "more than one canonical link", issues.RuleHeadCanonicalMultiple)
+		}
+	}
+
+	// The character encoding, early enough for browsers to find it
+	if len(charsets) == 0 {
+		hT.headIssue(document, nil, "character encoding not declared", issues.RuleHeadCharsetMissing)
+	} else if position, ok := document.NodePosition(charsets[0]); ok && position.Offset >= charsetPrescanBytes {
+		hT.headIssue(document, charsets[0], fmt.Sprintf(
+			"character encoding declared after the first %d bytes", charsetPrescanBytes),
+			issues.RuleHeadCharsetLate)
+	}
+}
+
+// Add an error for the element n, or for the document as a whole if n is nil
+// or wasn't in the source, as with an implied <html>.
+func (hT *HTMLTest) headIssue(document *htmldoc.Document, n *html.Node, message string, rule string) {
+	if _, ok := document.NodePosition(n); n == nil || !ok {
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:    issues.LevelError,
+			Document: document,
+			Message:  message,
+			Rule:     rule,
+		})
+		return
+	}
+	ref, _ := htmldoc.NewReference(document, n, "")
+	hT.issueStore.AddIssue(issues.Issue{
+		Level:     issues.LevelError,
+		Message:   message,
+		Rule:      rule,
+		Reference: ref,
+	})
+}
+
+// Text content of n, from its text node descendants
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		} else {
+			b.WriteString(nodeText(c))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package htmltest
+
+import (
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+	"github.com/wjdp/htmltest/issues"
+)
+
+func tTestHead(filename string, tOpts map[string]interface{}) *HTMLTest {
+	opts := map[string]interface{}{"CheckHead": true}
+	for key, value := range tOpts {
+		opts[key] = value
+	}
+	return tTestFileOpts(filename, opts)
+}
+
+func TestHeadDisabled(t *testing.T) {
+	// doesn't check the head by default
+	hT := tTestFile("fixtures/head/missing.html")
+	tExpectIssueCount(t, hT, 0)
+}
+
+func TestHeadValid(t *testing.T) {
+	// passes a head with everything in it, ignoring <title> in SVG
+	hT := tTestHead("fixtures/head/valid.html", nil)
+	tExpectIssueCount(t, hT, 0)
+}
+
+func TestHeadMissing(t *testing.T) {
+	// fails a document without any of the recommended tags
+	hT := tTestHead("fixtures/head/missing.html", nil)
+	tExpectIssueCount(t, hT, 4)
+	tExpectIssue(t, hT, "<html> missing lang attribute", 1)
+	tExpectIssue(t, hT, "<title> missing", 1)
+	tExpectIssue(t, hT, "meta description missing", 1)
+	tExpectIssue(t, hT, "character encoding not declared", 1)
+}
+
+func TestHeadBroken(t *testing.T) {
+	// fails broken tags at their position
+	hT := tTestHead("fixtures/head/broken.html", nil)
+	tExpectIssueCount(t, hT, 5)
+	lines := make(map[string]int)
+	for _, issue := range hT.issueStore.Issues(issues.LevelError) {
+		lines[issue.Rule] = issue.Reference.Line
+	}
+	assert.Equals(t, "charset", lines[issues.RuleHeadCharsetLate], 24)
+	assert.Equals(t, "title empty", lines[issues.RuleHeadTitleEmpty], 25)
+	assert.Equals(t, "title multiple", lines[issues.RuleHeadTitleMultiple], 26)
+	assert.Equals(t, "description", lines[issues.RuleHeadDescriptionLength], 27)
+	assert.Equals(t, "canonical", lines[issues.RuleHeadCanonicalMultiple], 29)
+	tExpectIssue(t, hT, "meta description is 10 characters, should be 50 to 160", 1)
+}
+
+func TestHeadDescriptionLength(t *testing.T) {
+	// description bounds are configurable
+	hT := tTestHead("fixtures/head/broken.html", map[string]interface{}{
+		"HeadDescriptionMinLength": 10,
+	})
+	tExpectIssueCount(t, hT, 4)
+	hT = tTestHead("fixtures/head/valid.html", map[string]interface{}{
+		"HeadDescriptionMaxLength": 80,
+	})
+	tExpectIssueCount(t, hT, 1)
+}
+
+func TestHeadRules(t *testing.T) {
+	// each check has its own rule
+	hT := tTestHead("fixtures/head/missing.html", map[string]interface{}{
+		"Rules": map[interface{}]interface{}{
+			"head/lang-missing":    "off",
+			"head/charset-missing": "warning",
+		},
+	})
+	tExpectIssueCount(t, hT, 2)
+}
+
+func TestHeadImpliedHTML(t *testing.T) {
+	// a missing lang on an implied <html> is an issue with the document
+	hT := tTestHead("fixtures/opengraph/url-valid.html", nil)
+	tExpectIssue(t, hT, "<html> missing lang attribute", 1)
+	for _, issue := range hT.issueStore.Issues(issues.LevelError) {
+		assert.Equals(t, "reference", issue.Reference == nil, true)
+	}
+}
@@ -0,0 +1,147 @@
+package htmltest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/issues"
+	"golang.org/x/net/html"
+)
+
+// hreflang value for the alternate used when no other language matches
+const hreflangDefault string = "x-default"
+
+// A BCP 47 language tag, RFC 5646 section 2.1: a language with optional
+// script, region, variants, extensions and private use subtags, a private use
+// tag alone, or one of the irregular grandfathered tags. Regular
+// grandfathered tags, e.g. zh-min-nan, match the langtag production.
+var bcp47Tag = regexp.MustCompile(`(?i)^(?:` +
+	`(?:[a-z]{2,3}(?:-[a-z]{3}){0,3}|[a-z]{4}|[a-z]{5,8})` + // language, with extlangs
+	`(?:-[a-z]{4})?` + // script
+	`(?:-(?:[a-z]{2}|[0-9]{3}))?` + // region
+	`(?:-(?:[a-z0-9]{5,8}|[0-9][a-z0-9]{3}))*` + // variants
+	`(?:-[0-9a-wy-z](?:-[a-z0-9]{2,8})+)*` + // extensions
+	`(?:-x(?:-[a-z0-9]{1,8})+)?` + // private use
+	`|x(?:-[a-z0-9]{1,8})+` +
+	`|en-gb-oed|i-ami|i-bnn|i-default|i-enochian|i-hak|i-klingon|i-lux|i-mingo|i-navajo|i-pwn|i-tao|i-tay|i-tsu` +
+	`|sgn-be-fr|sgn-be-nl|sgn-ch-de` +
+	`)$`)
+
+// The document's <link rel="alternate" hreflang> elements
+func hreflangLinks(document *htmldoc.Document) []*html.Node {
+	links := make([]*html.Node, 0)
+	for _, n := range document.NodesOfInterest {
+		if n.Data == "link" && htmldoc.AttrPresent(n.Attr, "hreflang") && hasRel(n, "alternate") {
+			links = append(links, n)
+		}
+	}
+	return links
+}
+
+// The document in the store the node's href points to, ok is false for
+// external and broken references.
+func (hT *HTMLTest) resolveLink(document *htmldoc.Document, node *html.Node) (*htmldoc.Document, *htmldoc.Reference, bool) {
+	ref, err := htmldoc.NewReference(document, node, htmldoc.GetAttr(node.Attr, "href"))
+	if err != nil || ref.Scheme() != "file" {
+		return nil, ref, false
+	}
+	target, ok := hT.documentStore.ResolvePath(ref.RefSitePath())
+	return target, ref, ok
+}
+
+// Checks the document's hreflang alternates, after it's been parsed. Codes
+// must be valid, the set must have an x-default, and each internal alternate
+// must link back to the document and not be canonicalised to another.
+func (hT *HTMLTest) checkHreflang(document *htmldoc.Document) {
+	links := hreflangLinks(document)
+	if len(links) == 0 {
+		return
+	}
+
+	hasDefault := false
+	for _, n := range links {
+		target, ref, ok := hT.resolveLink(document, n)
+		if ref == nil {
+			// Bad href, reported by checkLink
+			continue
+		}
+		ref.Attr = "hreflang"
+
+		code := strings.TrimSpace(htmldoc.GetAttr(n.Attr, "hreflang"))
+		if strings.EqualFold(code, hreflangDefault) {
+			hasDefault = true
+		} else if !bcp47Tag.MatchString(code) {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:     issues.LevelError,
+				Message:   fmt.Sprintf("hreflang %q is not a valid language tag", code),
+				Rule:      issues.RuleHreflangInvalid,
+				Reference: ref,
+			})
+		}
+
+		if !ok {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:     issues.LevelDebug,
+				Message:   "hreflang alternate not in the site, skipping reciprocity check",
+				Reference: ref,
+			})
+			continue
+		}
+		if target == document {
+			// Documents list themselves in their own set
+			continue
+		}
+
+		target.Parse()
+		if !hT.linksBack(target, document) {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:     issues.LevelError,
+				Message:   fmt.Sprintf("hreflang alternate %s does not link back", target.SitePath),
+				Rule:      issues.RuleHreflangNotReciprocal,
+				Reference: ref,
+			})
+		}
+		if canonical, ok := hT.canonicalDocument(target); ok && canonical != target {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level: issues.LevelError,
+				Message: fmt.Sprintf("hreflang alternate %s is canonicalised to %s",
+					target.SitePath, canonical.SitePath),
+				Rule:      issues.RuleHreflangCanonicalised,
+				Reference: ref,
+			})
+		}
+	}
+
+	if !hasDefault {
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:    issues.LevelError,
+			Document: document,
+			Message:  "hreflang alternates missing x-default",
+			Rule:     issues.RuleHreflangDefaultMissing,
+		})
+	}
+}
+
+// Does one of target's hreflang alternates point to document?
+func (hT *HTMLTest) linksBack(target *htmldoc.Document, document *htmldoc.Document) bool {
+	for _, n := range hreflangLinks(target) {
+		if back, _, ok := hT.resolveLink(target, n); ok && back == document {
+			return true
+		}
+	}
+	return false
+}
+
+// The document in the store the document's canonical link points to, ok is
+// false if it has none or it points outside the site.
+func (hT *HTMLTest) canonicalDocument(document *htmldoc.Document) (*htmldoc.Document, bool) {
+	for _, n := range document.NodesOfInterest {
+		if n.Data == "link" && hasRel(n, "canonical") {
+			canonical, _, ok := hT.resolveLink(document, n)
+			return canonical, ok
+		}
+	}
+	return nil, false
+}
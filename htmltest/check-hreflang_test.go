@@ -0,0 +1,43 @@
+package htmltest
+
+import (
+	"testing"
+)
+
+func TestHreflangTags(t *testing.T) {
+	// matches BCP 47 language tags
+	valid := []string{"en", "en-GB", "fr-CA", "zh-Hant-TW", "es-419", "sl-rozaj-biske",
+		"de-CH-1901", "en-a-bbb-x-a-ccc", "x-whatever", "i-klingon", "zh-yue-HK", "EN-gb"}
+	for _, code := range valid {
+		if !bcp47Tag.MatchString(code) {
+			t.Error("valid tag", code, "didn't match")
+		}
+	}
+	invalid := []string{"", "en_GB", "englishlang", "e", "en-", "en-GB-", "123", "en-x", "de-419-419"}
+	for _, code := range invalid {
+		if bcp47Tag.MatchString(code) {
+			t.Error("invalid tag", code, "matched")
+		}
+	}
+}
+
+func TestHreflangDisabled(t *testing.T) {
+	// doesn't check hreflang sets by default
+	hT := tTestDirectoryOpts("fixtures/hreflang", map[string]interface{}{
+		"CheckExternal": false,
+	})
+	tExpectIssueCount(t, hT, 0)
+}
+
+func TestHreflang(t *testing.T) {
+	// checks codes, x-default, reciprocity and canonicals of alternates
+	hT := tTestDirectoryOpts("fixtures/hreflang", map[string]interface{}{
+		"CheckExternal": false,
+		"CheckHreflang": true,
+	})
+	tExpectIssueCount(t, hT, 4)
+	tExpectIssue(t, hT, `hreflang "en_GB" is not a valid language tag`, 1)
+	tExpectIssue(t, hT, "hreflang alternate de/index.html does not link back", 1)
+	tExpectIssue(t, hT, "hreflang alternate es/index.html is canonicalised to en/index.html", 1)
+	tExpectIssue(t, hT, "hreflang alternates missing x-default", 1)
+}
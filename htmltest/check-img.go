@@ -20,24 +20,28 @@ func (hT *HTMLTest) checkImg(document *htmldoc.Document, node *html.Node) {
 			Level:    issues.LevelError,
 			Document: document,
 			Message:  fmt.Sprintf("bad reference: %q", err),
+			Rule:     issues.RuleRefInvalid,
 		})
 		return
 	}
+	ref.Attr = "src"
 
-	// Check alt present, fail if absent unless asked to ignore
-	if !htmldoc.AttrPresent(node.Attr, "alt") && !hT.opts.IgnoreAltMissing {
+	// Check alt present, fail if absent
+	if !htmldoc.AttrPresent(node.Attr, "alt") {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelError,
 			Message:   "alt attribute missing",
+			Rule:      issues.RuleImgAltMissing,
 			Reference: ref,
 		})
-	} else if htmldoc.AttrPresent(node.Attr, "alt") && !hT.opts.IgnoreAltMissing {
+	} else {
 		// Following checks require alt attr is present
-		if len(attrs["alt"]) == 0 && !hT.opts.IgnoreAltEmpty {
+		if len(attrs["alt"]) == 0 {
 			// Check alt has length, fail if empty
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   "alt text empty",
+				Rule:      issues.RuleImgAltEmpty,
 				Reference: ref,
 			})
 		}
@@ -46,16 +50,21 @@ func (hT *HTMLTest) checkImg(document *htmldoc.Document, node *html.Node) {
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   "alt text contains only whitespace",
+				Rule:      issues.RuleImgAltWhitespace,
 				Reference: ref,
 			})
 		}
 	}
 
+	// Check responsive image candidates
+	hT.checkSrcset(document, node, "srcset")
+
 	// Check src present, fail if absent
 	if !htmldoc.AttrPresent(node.Attr, "src") {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelError,
 			Message:   "src attribute missing",
+			Rule:      issues.RuleImgSrcMissing,
 			Reference: ref,
 		})
 		return
@@ -64,6 +73,7 @@ func (hT *HTMLTest) checkImg(document *htmldoc.Document, node *html.Node) {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelError,
 			Message:   "src attribute empty",
+			Rule:      issues.RuleImgSrcEmpty,
 			Reference: ref,
 		})
 		return
@@ -77,20 +87,24 @@ func (hT *HTMLTest) checkImg(document *htmldoc.Document, node *html.Node) {
 				Level:    issues.LevelError,
 				Document: document,
 				Message:  fmt.Sprintf("bad reference: %q", err),
+				Rule:     issues.RuleRefInvalid,
 			})
 			return
 		}
+		usemapRef.Attr = "usemap"
 
 		if len(usemapRef.URL.Path) > 0 {
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   "only fragment starting with # allowed in usemap attribute",
+				Rule:      issues.RuleImgUsemapInvalid,
 				Reference: ref,
 			})
 		} else if len(usemapRef.URL.Fragment) == 0 {
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   "usemap empty",
+				Rule:      issues.RuleImgUsemapEmpty,
 				Reference: ref,
 			})
 		} else {
@@ -102,12 +116,14 @@ func (hT *HTMLTest) checkImg(document *htmldoc.Document, node *html.Node) {
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   "<img> with usemap attribute not allowed as descendant of an <a> element",
+				Rule:      issues.RuleImgUsemapNested,
 				Reference: ref,
 			})
 		} else if parent.Data == "button" {
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   "<img> with usemap attribute not allowed as descendant of a <button>",
+				Rule:      issues.RuleImgUsemapNested,
 				Reference: ref,
 			})
 		}
@@ -156,8 +156,21 @@ func TestImageSrcSet(t *testing.T) {
 func TestImageSrcSetMissing(t *testing.T) {
 	// fails for images with an alt but missing src or srcset
 	hT := tTestFile("fixtures/images/srcSetMissingImage.html")
-	tExpectIssueCount(t, hT, 1)
+	tExpectIssueCount(t, hT, 2)
 	tExpectIssue(t, hT, "src attribute missing", 1)
+	tExpectIssue(t, hT, "target does not exist", 1)
+}
+
+func TestImageSrcSetCandidates(t *testing.T) {
+	// fails for srcset candidates which are missing or malformed
+	hT := tTestFile("fixtures/images/srcSetCandidates.html")
+	tExpectIssueCount(t, hT, 7)
+	tExpectIssue(t, hT, "target does not exist", 2)
+	tExpectIssue(t, hT, "srcset candidate: invalid width descriptor \"-100w\"", 1)
+	tExpectIssue(t, hT, "srcset candidate: unknown descriptor \"2\"", 1)
+	tExpectIssue(t, hT, "srcset mixes width and density descriptors", 1)
+	tExpectIssue(t, hT, "srcset has width descriptors but sizes is missing", 1)
+	tExpectIssue(t, hT, "<img> srcset has no image candidates", 1)
 }
 
 func TestImageSrcSetMissingAlt(t *testing.T) {
@@ -169,7 +182,15 @@ func TestImageSrcSetMissingAlt(t *testing.T) {
 
 func TestImageSrcSetMissingAltIgnore(t *testing.T) {
 	// ignores missing alt tags when asked for srcset
+	// the protocol-relative srcset is external, it isn't fetched here
 	hT := tTestFileOpts("fixtures/images/srcSetIgnorable.html",
+		map[string]interface{}{"IgnoreAltMissing": true, "CheckExternal": false})
+	tExpectIssueCount(t, hT, 0)
+}
+
+func TestImageSrcSetMissingAltIgnoreLocal(t *testing.T) {
+	// ignores missing alt tags when asked for srcset of local images
+	hT := tTestFileOpts("fixtures/images/srcSetIgnorableLocal.html",
 		map[string]interface{}{"IgnoreAltMissing": true})
 	tExpectIssueCount(t, hT, 0)
 }
@@ -224,6 +245,25 @@ func TestImageAltIgnoreMissingWithBlank(t *testing.T) {
 	tExpectIssueCount(t, hT, 0)
 }
 
+func TestImageAltRules(t *testing.T) {
+	// reports alt issues at the level set in Rules
+	hT := tTestFileOpts("fixtures/images/emptyImageAltText.html",
+		map[string]interface{}{"Rules": map[interface{}]interface{}{
+			"img/alt-whitespace": "warning",
+		}})
+	tExpectIssueCount(t, hT, 2)
+	tExpectIssue(t, hT, "alt text contains only whitespace", 1)
+}
+
+func TestImageAltRuleOff(t *testing.T) {
+	// drops alt issues of rules turned off, as IgnoreAltMissing does
+	hT := tTestFileOpts("fixtures/images/missingImageAlt.html",
+		map[string]interface{}{"Rules": map[interface{}]interface{}{
+			"img/alt-missing": "off",
+		}})
+	tExpectIssueCount(t, hT, 0)
+}
+
 func TestBrokenImagePre(t *testing.T) {
 	// we no longer ignore image issues in pre / code tags
 	hT := tTestFile("fixtures/images/badImagesInPre.html")
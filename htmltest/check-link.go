@@ -2,17 +2,20 @@ package htmltest
 
 import (
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/badoux/checkmail"
 	"github.com/wjdp/htmltest/htmldoc"
 	"github.com/wjdp/htmltest/issues"
 	"github.com/wjdp/htmltest/output"
+	"github.com/wjdp/htmltest/refcache"
 	"golang.org/x/net/html"
 )
 
@@ -40,6 +43,15 @@ func (hT *HTMLTest) checkLink(document *htmldoc.Document, node *html.Node) {
 		}
 	}
 
+	// Preloaded responsive images give their candidates in imagesrcset, href
+	// may then be left out
+	if node.Data == "link" && htmldoc.AttrPresent(node.Attr, "imagesrcset") {
+		hT.checkSrcset(document, node, "imagesrcset")
+		if !htmldoc.AttrPresent(node.Attr, "href") {
+			return
+		}
+	}
+
 	// Create reference
 	ref, err := htmldoc.NewReference(document, node, attrs["href"])
 	if err != nil {
@@ -47,9 +59,11 @@ func (hT *HTMLTest) checkLink(document *htmldoc.Document, node *html.Node) {
 			Level:    issues.LevelError,
 			Document: document,
 			Message:  fmt.Sprintf("bad reference: %q", err),
+			Rule:     issues.RuleRefInvalid,
 		})
 		return
 	}
+	ref.Attr = "href"
 
 	// Check for missing href, fail for link nodes
 	if !htmldoc.AttrPresent(node.Attr, "href") {
@@ -65,6 +79,7 @@ func (hT *HTMLTest) checkLink(document *htmldoc.Document, node *html.Node) {
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   "<link> missing href",
+				Rule:      issues.RuleLinkHrefMissing,
 				Reference: ref,
 			})
 			return
@@ -73,29 +88,29 @@ func (hT *HTMLTest) checkLink(document *htmldoc.Document, node *html.Node) {
 
 	// Blank href
 	if attrs["href"] == "" {
-		if !hT.opts.IgnoreEmptyHref {
-			var msg string = fmt.Sprintf("<%s> href blank", node.Data)
-			if attrs["title"] != "" {
-				msg = fmt.Sprintf("%s title=%q", msg, attrs["title"])
-			}
-			if ref.Node.FirstChild != nil {
-				msg = fmt.Sprintf("%s body=%q", msg, ref.Node.FirstChild.Data)
-			}
-			hT.issueStore.AddIssue(issues.Issue{
-				Level:     issues.LevelError,
-				Message:   msg,
-				Reference: ref,
-			})
+		var msg string = fmt.Sprintf("<%s> href blank", node.Data)
+		if attrs["title"] != "" {
+			msg = fmt.Sprintf("%s title=%q", msg, attrs["title"])
+		}
+		if ref.Node.FirstChild != nil {
+			msg = fmt.Sprintf("%s body=%q", msg, ref.Node.FirstChild.Data)
 		}
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:     issues.LevelError,
+			Message:   msg,
+			Rule:      issues.RuleLinkHrefBlank,
+			Reference: ref,
+		})
 		return
 	}
 
 	// href="#"
 	if attrs["href"] == "#" {
-		if hT.opts.CheckInternalHash && !hT.opts.IgnoreInternalEmptyHash {
+		if hT.opts.CheckInternalHash {
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   fmt.Sprintf("<%s> empty hash", node.Data),
+				Rule:      issues.RuleLinkHashEmpty,
 				Reference: ref,
 			})
 		}
@@ -111,6 +126,9 @@ func (hT *HTMLTest) checkLink(document *htmldoc.Document, node *html.Node) {
 		hT.checkExternal(ref)
 	case "file":
 		hT.checkInternal(ref)
+		if hT.opts.CheckCSS && node.Data == "link" && hasRel(node, "stylesheet") {
+			hT.checkStylesheet(ref)
+		}
 	case "self":
 		hT.checkInternalHash(ref)
 	case "mailto":
@@ -127,10 +145,8 @@ func (hT *HTMLTest) checkLink(document *htmldoc.Document, node *html.Node) {
 }
 
 func (hT *HTMLTest) checkExternal(ref *htmldoc.Reference) {
-	issueLevel := issues.LevelError
-	if hT.opts.IgnoreExternalBrokenLinks {
-		issueLevel = issues.LevelWarning
-	}
+	hT.recordReference(ref, nil)
+
 	if !hT.opts.CheckExternal {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelDebug,
@@ -150,138 +166,358 @@ func (hT *HTMLTest) checkExternal(ref *htmldoc.Reference) {
 	if hT.opts.StripQueryString && !InList(hT.opts.StripQueryExcludes, urlStr) {
 		urlStr = htmldoc.URLStripQueryString(urlStr)
 	}
-	var statusCode int
 
-	cR, isCached := hT.refCache.Get(urlStr)
+	// Do we need the body of the page to check the hash exists?
+	checkHash := hT.opts.CheckExternalHash && len(ref.URL.Fragment) > 0
 
-	if isCached && statusCodeValid(cR.StatusCode) {
-		// If we have a valid result in cache, use that
-		statusCode = cR.StatusCode
-		hT.issueStore.AddIssue(issues.Issue{
-			Level:     issues.LevelDebug,
-			Message:   "from cache",
-			Reference: ref,
-		})
-	} else {
-		hT.issueStore.AddIssue(issues.Issue{
-			Level:     issues.LevelDebug,
-			Message:   "fresh",
-			Reference: ref,
-		})
+	if hT.pipeline != nil {
+		// Collecting references, the request is made later in a batch
+		hT.pipeline.add(ref, urlStr, checkHash)
+		return
+	}
+
+	hT.reportExternal(ref, urlStr, checkHash, hT.fetchExternal(ref, urlStr, checkHash))
+}
 
-		// Build the request
-		req, err := http.NewRequest("GET", urlStr, nil)
-		// Only error NewRequest raises is if the url isn't valid, we have already checked it by this point so OK just
-		// to panic if err != nil.
-		output.CheckErrorPanic(err)
+// reportExternal : Add issues for ref given the result of fetching its URL.
+func (hT *HTMLTest) reportExternal(ref *htmldoc.Reference, urlStr string, checkHash bool,
+	result *externalResult) {
+	attempts := result.attempts
 
-		// Set UA header
-		req.Header.Set("User-Agent", "htmltest/"+hT.opts.Version)
+	if err := result.err; err != nil {
+		if strings.Contains(err.Error(), "Client.Timeout") {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:     issues.LevelError,
+				Message:   "request exceeded our ExternalTimeout" + attemptsText(attempts),
+				Rule:      issues.RuleExternalTimeout,
+				Reference: ref,
+			})
+			return
+		}
 
-		// Set headers from HTTPHeaders option
-		for key, value := range hT.opts.HTTPHeaders {
-			// Due to the way we're loading in config these keys and values are interface{}. In normal cases they are
-			// strings, but could very easily be ints (side note: this isn't great, we'll fix this later, #73)
-			req.Header.Set(fmt.Sprintf("%v", key), fmt.Sprintf("%v", value))
+		if result.incompleteChain {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:     issues.LevelWarning,
+				Reference: ref,
+				Message:   "incomplete certificate chain",
+				Rule:      issues.RuleExternalCertChain,
+			})
+			return
 		}
 
-		hT.httpChannel <- true // Add to http concurrency limiter
+		// More generic, should be kept below more specific cases
+		if strings.Contains(err.Error(), "dial tcp") {
+			// Remove long prefix
+			prefix := "Get " + urlStr + ": dial tcp: lookup "
+			cleanedMessage := strings.TrimPrefix(err.Error(), prefix)
+			// Add error
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:     issues.LevelError,
+				Message:   cleanedMessage + attemptsText(attempts),
+				Rule:      issues.RuleExternalError,
+				Reference: ref,
+			})
+			return
+		}
 
+		// Unhandled client error, return generic error
 		hT.issueStore.AddIssue(issues.Issue{
-			Level:     issues.LevelInfo,
-			Message:   "hitting",
+			Level:     issues.LevelError,
+			Message:   err.Error() + attemptsText(attempts),
+			Rule:      issues.RuleExternalError,
 			Reference: ref,
 		})
 
-		resp, err := hT.httpClient.Do(req)
-
-		<-hT.httpChannel // Bump off http concurrency limiter
-
-		if err != nil {
-			if strings.Contains(err.Error(), "Client.Timeout") {
-				hT.issueStore.AddIssue(issues.Issue{
-					Level:     issueLevel,
-					Message:   "request exceeded our ExternalTimeout",
-					Reference: ref,
-				})
-				return
-			}
+		return
+	}
 
-			if certErr, ok := err.(*url.Error).Err.(x509.UnknownAuthorityError); ok {
-				err = validateCertChain(certErr.Cert)
-				if err == nil {
-					hT.issueStore.AddIssue(issues.Issue{
-						Level:     issues.LevelWarning,
-						Reference: ref,
-						Message:   "incomplete certificate chain",
-					})
-					return
-				}
-			}
+	statusCode := result.statusCode
+	acceptedLevel, accepted := hT.acceptedStatus(urlStr, statusCode)
 
-			// More generic, should be kept below more specific cases
-			if strings.Contains(err.Error(), "dial tcp") {
-				// Remove long prefix
-				prefix := "Get " + urlStr + ": dial tcp: lookup "
-				cleanedMessage := strings.TrimPrefix(err.Error(), prefix)
-				// Add error
-				hT.issueStore.AddIssue(issues.Issue{
-					Level:     issueLevel,
-					Message:   cleanedMessage,
-					Reference: ref,
-				})
-				return
+	switch {
+	case statusCode == http.StatusOK:
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:      issues.LevelDebug,
+			Message:    http.StatusText(statusCode),
+			Reference:  ref,
+			StatusCode: statusCode,
+			URL:        urlStr,
+		})
+	case statusCode == http.StatusPartialContent:
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:      issues.LevelDebug,
+			Message:    http.StatusText(statusCode),
+			Reference:  ref,
+			StatusCode: statusCode,
+			URL:        urlStr,
+		})
+	case accepted:
+		// Allowed by AcceptStatusCodes
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:      acceptedLevel,
+			Message:    fmt.Sprintf("%s %d", "Accepted status:", statusCode) + attemptsText(attempts),
+			Rule:       issues.RuleExternalAccepted,
+			Reference:  ref,
+			StatusCode: statusCode,
+			URL:        urlStr,
+		})
+	default:
+		if ref.Node != nil && htmldoc.GetAttr(ref.Node.Attr, "rel") == "canonical" {
+			// Level comes from Rules, IgnoreCanonicalBrokenLinks is an alias
+			// making it a warning. Without the alias it reads as any other
+			// broken link.
+			message := http.StatusText(statusCode) + " [rel=\"canonical\"]"
+			if !hT.opts.IgnoreCanonicalBrokenLinks {
+				message = fmt.Sprintf("%s %d", "Non-OK status:", statusCode)
 			}
-
-			// Unhandled client error, return generic error
 			hT.issueStore.AddIssue(issues.Issue{
-				Level:     issueLevel,
-				Message:   err.Error(),
-				Reference: ref,
+				Level:      issues.LevelError,
+				Message:    message + attemptsText(attempts),
+				Rule:       issues.RuleExternalCanonical,
+				Reference:  ref,
+				StatusCode: statusCode,
+				URL:        urlStr,
+			})
+		} else {
+			// Failed VCRed requests end up here with a status code of zero
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:      issues.LevelError,
+				Message:    fmt.Sprintf("%s %d", "Non-OK status:", statusCode) + attemptsText(attempts),
+				Rule:       issues.RuleExternalStatus,
+				Reference:  ref,
+				StatusCode: statusCode,
+				URL:        urlStr,
 			})
-
-			return
 		}
-		// Save cached result
-		hT.refCache.Save(urlStr, resp.StatusCode)
-		statusCode = resp.StatusCode
 	}
 
-	switch statusCode {
-	case http.StatusOK:
+	hT.reportRedirects(ref, urlStr, result.redirects)
+
+	if checkHash && statusCodeValid(statusCode) && !hashInList(result.hashes, ref.URL.Fragment) {
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:      issues.LevelError,
+			Message:    "external hash does not exist",
+			Rule:       issues.RuleExternalHashMissing,
+			Reference:  ref,
+			StatusCode: statusCode,
+			URL:        urlStr,
+		})
+	}
+}
+
+// externalResult : Outcome of fetching an external URL. Shared between every
+// reference to the URL that wanted it at the same time.
+type externalResult struct {
+	statusCode      int                 // Status code of the final response
+	hashes          []string            // id/names on the page, nil unless the body was read
+	attempts        int                 // Number of requests made, 1 when from cache
+	err             error               // Error from the http client, if any
+	incompleteChain bool                // err was due to an incomplete, but valid, certificate chain
+	redirects       []refcache.Redirect // Redirects followed to get the final response
+}
+
+// fetchExternal : Get the status, and hashes if checkHash, of urlStr. Uses the
+// refCache where possible, otherwise makes the request. Concurrent fetches of
+// the same URL are coalesced into a single request.
+func (hT *HTMLTest) fetchExternal(ref *htmldoc.Reference, urlStr string, checkHash bool) *externalResult {
+	// The fragment is never sent to the server, one cache entry serves all
+	// hashes on a page
+	cacheKey := htmldoc.URLStripFragment(urlStr)
+
+	if result, ok := hT.cachedExternal(cacheKey, checkHash); ok {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelDebug,
-			Message:   http.StatusText(statusCode),
+			Message:   "from cache",
 			Reference: ref,
 		})
-	case http.StatusPartialContent:
+		return result
+	}
+
+	result, shared := hT.externalRequests.do(requestKey{cacheKey, checkHash}, func() *externalResult {
+		// Another flight may have filled the cache as we arrived
+		if result, ok := hT.cachedExternal(cacheKey, checkHash); ok {
+			return result
+		}
+		return hT.requestExternal(ref, urlStr, cacheKey, checkHash)
+	})
+
+	if shared {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelDebug,
-			Message:   http.StatusText(statusCode),
+			Message:   "shared in-flight request",
 			Reference: ref,
 		})
+	}
+	return result
+}
+
+// Return a usable result from the refCache, if there is one. Failures are
+// kept as long as the CacheExpires* option for their outcome allows.
+func (hT *HTMLTest) cachedExternal(cacheKey string, checkHash bool) (*externalResult, bool) {
+	cR, isCached := hT.refCache.Get(cacheKey)
+	if !isCached {
+		return nil, false
+	}
+	if cR.Outcome == "" && hT.externalOutcome(cacheKey, cR.StatusCode) != refcache.OutcomeSuccess {
+		// Saved by an older version which didn't record outcomes
+		return nil, false
+	}
+	if checkHash && statusCodeValid(cR.StatusCode) && cR.Hashes == nil {
+		// Only have the status, need to fetch the body for the hashes
+		return nil, false
+	}
+
+	result := &externalResult{
+		statusCode: cR.StatusCode,
+		hashes:     cR.Hashes,
+		attempts:   1,
+		redirects:  cR.Redirects,
+	}
+	if cR.Error != "" {
+		result.err = errors.New(cR.Error)
+		result.incompleteChain = cR.ErrorClass == errorClassIncompleteChain
+	}
+	return result, true
+}
+
+// Which refcache outcome does a response with statusCode count as? Codes
+// allowed by AcceptStatusCodes count as success.
+func (hT *HTMLTest) externalOutcome(urlStr string, statusCode int) string {
+	_, accepted := hT.acceptedStatus(urlStr, statusCode)
+	switch {
+	case statusCodeValid(statusCode) || accepted:
+		return refcache.OutcomeSuccess
+	case statusCode >= 500 && statusCode < 600:
+		return refcache.OutcomeServerError
 	default:
-		attrs := htmldoc.ExtractAttrs(ref.Node.Attr, []string{"rel"})
-		if attrs["rel"] == "canonical" && hT.opts.IgnoreCanonicalBrokenLinks {
-			hT.issueStore.AddIssue(issues.Issue{
-				Level:     issues.LevelWarning,
-				Message:   http.StatusText(statusCode) + " [rel=\"canonical\"]",
-				Reference: ref,
-			})
-		} else {
-			// Failed VCRed requests end up here with a status code of zero
-			hT.issueStore.AddIssue(issues.Issue{
-				Level:     issueLevel,
-				Message:   fmt.Sprintf("%s %d", "Non-OK status:", statusCode),
-				Reference: ref,
-			})
+		return refcache.OutcomeClientError
+	}
+}
+
+// Make the request for urlStr and save the outcome to the refCache.
+func (hT *HTMLTest) requestExternal(ref *htmldoc.Reference, urlStr string, cacheKey string,
+	checkHash bool) *externalResult {
+	hT.issueStore.AddIssue(issues.Issue{
+		Level:     issues.LevelDebug,
+		Message:   "fresh",
+		Reference: ref,
+	})
+
+	method := "GET"
+	if !checkHash {
+		// Checking a hash needs the body, otherwise use the configured method
+		method = hT.requestMethod(urlStr)
+	}
+
+	hT.issueStore.AddIssue(issues.Issue{
+		Level:     issues.LevelInfo,
+		Message:   "hitting",
+		Reference: ref,
+	})
+
+	attempted := time.Now()
+	resp, attempts, err := hT.doRequest(hT.newExternalRequest(method, urlStr, checkHash))
+	headRefused := false
+
+	if err == nil && method == "HEAD" && headUnsupported(resp.StatusCode) {
+		// Server doesn't answer HEAD properly, fall back to GET
+		resp.Body.Close()
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:     issues.LevelDebug,
+			Message:   fmt.Sprintf("HEAD gave %d, falling back to GET", resp.StatusCode),
+			Reference: ref,
+		})
+		method = "GET"
+		headRefused = true
+		var getAttempts int
+		resp, getAttempts, err = hT.doRequest(hT.newExternalRequest(method, urlStr, checkHash))
+		attempts += getAttempts
+	}
+	result := &externalResult{attempts: attempts, err: err}
+
+	if err != nil {
+		if certErr, ok := err.(*url.Error).Err.(x509.UnknownAuthorityError); ok {
+			result.incompleteChain = validateCertChain(certErr.Cert) == nil
 		}
+		class := errorClass(err)
+		if result.incompleteChain {
+			class = errorClassIncompleteChain
+		}
+		hT.refCache.SaveRef(cacheKey, refcache.CachedRef{
+			Method:     method,
+			Outcome:    refcache.OutcomeNetworkError,
+			ErrorClass: class,
+			Error:      err.Error(),
+			Attempted:  attempted,
+		})
+		return result
+	}
+	defer resp.Body.Close()
+	result.statusCode = resp.StatusCode
+	result.redirects = redirectChain(resp)
+
+	if checkHash && statusCodeValid(resp.StatusCode) {
+		result.hashes = extractResponseHashes(resp)
 	}
 
-	// TODO check a hash id exists in external page if present in reference (URL.Fragment)
+	// Save cached result
+	hT.refCache.SaveRef(cacheKey, refcache.CachedRef{
+		StatusCode:  result.statusCode,
+		Hashes:      result.hashes,
+		Method:      method,
+		HeadRefused: headRefused,
+		Redirects:   result.redirects,
+		Outcome:     hT.externalOutcome(cacheKey, result.statusCode),
+		FinalURL:    resp.Request.URL.String(),
+		ContentType: resp.Header.Get("Content-Type"),
+		Attempted:   attempted,
+	})
+	return result
+}
+
+// Build an external request for urlStr with our headers set.
+func (hT *HTMLTest) newExternalRequest(method string, urlStr string, checkHash bool) *http.Request {
+	req, err := http.NewRequest(method, urlStr, nil)
+	// Only error NewRequest raises is if the url isn't valid, we have already checked it by this point so OK just
+	// to panic if err != nil.
+	output.CheckErrorPanic(err)
+
+	// Set UA header
+	req.Header.Set("User-Agent", "htmltest/"+hT.opts.Version)
+
+	// Set headers from HTTPHeaders option
+	for key, value := range hT.opts.HTTPHeaders {
+		// Due to the way we're loading in config these keys and values are interface{}. In normal cases they are
+		// strings, but could very easily be ints (side note: this isn't great, we'll fix this later, #73)
+		req.Header.Set(fmt.Sprintf("%v", key), fmt.Sprintf("%v", value))
+	}
+
+	if checkHash {
+		// We need the whole body to find the hash
+		req.Header.Del("Range")
+	}
+	return req
+}
+
+// Which method to first try for urlStr. HEAD is skipped for hosts the cache
+// knows have needed GET.
+func (hT *HTMLTest) requestMethod(urlStr string) string {
+	method := hT.opts.httpMethod(urlStr)
+	if method == "HEAD" {
+		if u, err := url.Parse(urlStr); err == nil {
+			if hostMethod, ok := hT.refCache.HostMethod(u.Host); ok && hostMethod == "GET" {
+				return "GET"
+			}
+		}
+	}
+	return method
 }
 
 func (hT *HTMLTest) checkInternal(ref *htmldoc.Reference) {
+	// The site graph has every link, checked or not
+	refDoc, refExists := hT.documentStore.ResolveRef(ref)
+	hT.recordReference(ref, refDoc)
+
 	if !hT.opts.CheckInternal {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelDebug,
@@ -294,22 +530,22 @@ func (hT *HTMLTest) checkInternal(ref *htmldoc.Reference) {
 	urlStr := ref.URLString()
 
 	// Does this internal url match either a standard URL ignore rule or internal
-	// url ignore rule?
-	if hT.opts.isInternalURLIgnored(urlStr) || hT.opts.isURLIgnored(urlStr) {
+	// url ignore rule? Absolute URLs under a BaseURL are also ignored as given.
+	if hT.opts.isInternalURLIgnored(urlStr) || hT.opts.isURLIgnored(urlStr) ||
+		(ref.BaseURL != "" && hT.opts.isURLIgnored(ref.Path)) {
 		return
 	}
 
 	// First lookup in document store,
-	refDoc, refExists := hT.documentStore.ResolveRef(ref)
-
 	if refExists {
 		// If the resolved ref is an index.html and the path doesn't end in a
 		// trailing slash (and isn't linking directly to the index), complain.
-		if !hT.opts.IgnoreDirectoryMissingTrailingSlash && path.Base(refDoc.SitePath) == hT.opts.DirectoryIndex &&
+		if hT.issueStore.RuleEnabled(issues.RuleLinkDirectoryNoSlash) && path.Base(refDoc.SitePath) == hT.opts.DirectoryIndex &&
 			!strings.HasSuffix(ref.URL.Path, hT.opts.DirectoryIndex) && !strings.HasSuffix(ref.URL.Path, "/") {
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   "target is a directory, href lacks trailing slash",
+				Rule:      issues.RuleLinkDirectoryNoSlash,
 				Reference: ref,
 			})
 			refExists = false
@@ -340,6 +576,7 @@ func (hT *HTMLTest) checkInternalHash(ref *htmldoc.Reference) {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelError,
 			Message:   "missing hash",
+			Rule:      issues.RuleLinkHashEmpty,
 			Reference: ref,
 		})
 	}
@@ -352,6 +589,7 @@ func (hT *HTMLTest) checkInternalHash(ref *htmldoc.Reference) {
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   "hash does not exist",
+				Rule:      issues.RuleLinkHashMissing,
 				Reference: ref,
 			})
 		}
@@ -361,6 +599,7 @@ func (hT *HTMLTest) checkInternalHash(ref *htmldoc.Reference) {
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   "hash does not exist",
+				Rule:      issues.RuleLinkHashMissing,
 				Reference: ref,
 			})
 		}
@@ -373,6 +612,7 @@ func (hT *HTMLTest) checkFile(ref *htmldoc.Reference, absPath string) bool {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelError,
 			Message:   "target does not exist",
+			Rule:      issues.RuleLinkTargetMissing,
 			Reference: ref,
 		})
 		return false
@@ -385,6 +625,7 @@ func (hT *HTMLTest) checkFile(ref *htmldoc.Reference, absPath string) bool {
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   "target is a directory, no index",
+				Rule:      issues.RuleLinkDirectoryNoIndex,
 				Reference: ref,
 			})
 			return false
@@ -401,6 +642,7 @@ func (hT *HTMLTest) checkMailto(ref *htmldoc.Reference) {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelError,
 			Message:   "mailto is empty",
+			Rule:      issues.RuleMailtoEmpty,
 			Reference: ref,
 		})
 		return
@@ -410,6 +652,7 @@ func (hT *HTMLTest) checkMailto(ref *htmldoc.Reference) {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelError,
 			Message:   fmt.Sprintf("cannot decode email (%s): '%s'", decodeErr, ref.URL.Opaque),
+			Rule:      issues.RuleMailtoInvalid,
 			Reference: ref,
 		})
 		return
@@ -419,6 +662,7 @@ func (hT *HTMLTest) checkMailto(ref *htmldoc.Reference) {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelError,
 			Message:   fmt.Sprintf("invalid email address (%s): '%s'", formatErr, emailAddress),
+			Rule:      issues.RuleMailtoInvalid,
 			Reference: ref,
 		})
 		return
@@ -433,6 +677,7 @@ func (hT *HTMLTest) checkTel(ref *htmldoc.Reference) {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelError,
 			Message:   "tel is empty",
+			Rule:      issues.RuleTelEmpty,
 			Reference: ref,
 		})
 		return
@@ -1,9 +1,18 @@
 package htmltest
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
+	"github.com/daviddengcn/go-assert"
 	"github.com/wjdp/htmltest/issues"
+	"github.com/wjdp/htmltest/refcache"
 )
 
 // Spec tests
@@ -82,11 +91,32 @@ func TestAnchorExternalHashBrokenDefault(t *testing.T) {
 
 func TestAnchorExternalHashBrokenOption(t *testing.T) {
 	// fails for broken external hashes when asked
-	t.Skip("Not yet implemented")
-	hT := tTestFileOpts("fixtures/links/brokenHashOnTheWeb.html",
-		map[string]interface{}{"VCREnable": true})
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<h1 id="exists">Heading</h1>`)
+	}))
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/hashExternal.html", server,
+		map[string]interface{}{"CheckExternalHash": true})
 	tExpectIssueCount(t, hT, 1)
-	tExpectIssue(t, hT, "no such hash", 1)
+	tExpectIssue(t, hT, "external hash does not exist", 1)
+	// hashes are cached, the page is only fetched once
+	assert.Equals(t, "server hits", hits, 1)
+}
+
+func TestAnchorExternalHashBrokenOptionNotHTML(t *testing.T) {
+	// fails for external hashes into pages that aren't HTML
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, `<h1 id="exists">Heading</h1>`)
+	}))
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/hashExternal.html", server,
+		map[string]interface{}{"CheckExternalHash": true})
+	tExpectIssueCount(t, hT, 2)
+	tExpectIssue(t, hT, "external hash does not exist", 2)
 }
 
 func TestAnchorExternalCache(t *testing.T) {
@@ -325,6 +355,172 @@ func TestAnchorExternalQueryStringStripQueryExcludesDiffers(t *testing.T) {
 	tExpectIssue(t, hT, "Non-OK status: 400", 1)
 }
 
+// Serves pages which answer GET, and HEAD unless the path starts /nohead
+func tMethodServer(methods *[]string) *httptest.Server {
+	var mutex sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		*methods = append(*methods, r.Method+" "+r.URL.Path)
+		mutex.Unlock()
+		if r.Method == "HEAD" && strings.HasPrefix(r.URL.Path, "/nohead") {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestHTTPMethodDefault(t *testing.T) {
+	// sends GET requests by default
+	methods := make([]string, 0)
+	server := tMethodServer(&methods)
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/serverLink.html", server, map[string]interface{}{})
+	tExpectIssueCount(t, hT, 0)
+	assert.StringEquals(t, "requests", methods, []string{"GET /page.html"})
+}
+
+func TestHTTPMethodHead(t *testing.T) {
+	// sends HEAD requests when asked
+	methods := make([]string, 0)
+	server := tMethodServer(&methods)
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/serverLink.html", server,
+		map[string]interface{}{"HTTPMethod": "HEAD"})
+	tExpectIssueCount(t, hT, 0)
+	assert.StringEquals(t, "requests", methods, []string{"HEAD /page.html"})
+}
+
+func TestHTTPMethodHeadFallback(t *testing.T) {
+	// falls back to GET when HEAD isn't supported, and remembers that for the host
+	methods := make([]string, 0)
+	server := tMethodServer(&methods)
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/headFallback.html", server,
+		map[string]interface{}{"HTTPMethod": "HEAD"})
+	tExpectIssueCount(t, hT, 0)
+	assert.StringEquals(t, "requests", methods,
+		[]string{"HEAD /nohead.html", "GET /nohead.html", "GET /page.html"})
+}
+
+func TestHTTPMethodURLs(t *testing.T) {
+	// sends HEAD requests to URLs matching HTTPMethodURLs
+	methods := make([]string, 0)
+	server := tMethodServer(&methods)
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/headFallback.html", server,
+		map[string]interface{}{
+			"HTTPMethodURLs": map[interface{}]interface{}{"/page\\.html$": "head"},
+		})
+	tExpectIssueCount(t, hT, 0)
+	assert.StringEquals(t, "requests", methods,
+		[]string{"GET /nohead.html", "HEAD /page.html"})
+}
+
+// Answers /status/<code> with that status code, counting requests
+func tStatusServer(hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		code, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/status/"))
+		if err != nil {
+			code = http.StatusNotFound
+		}
+		w.WriteHeader(code)
+	}))
+}
+
+func TestAcceptStatusCodesDefault(t *testing.T) {
+	// only 200 and 206 pass by default
+	var hits int32
+	server := tStatusServer(&hits)
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/statusCodes.html", server, map[string]interface{}{})
+	tExpectIssueCount(t, hT, 3)
+}
+
+func TestAcceptStatusCodes(t *testing.T) {
+	// accepts codes and ranges listed for matching URLs, at the given level
+	var hits int32
+	server := tStatusServer(&hits)
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/statusCodes.html", server,
+		map[string]interface{}{
+			"AcceptStatusCodes": map[interface{}]interface{}{
+				"/status/": map[interface{}]interface{}{
+					"Codes": []interface{}{999, "400-403"},
+					"Level": "warning",
+				},
+			},
+		})
+	tExpectIssueCount(t, hT, 1)
+	tExpectIssue(t, hT, "Non-OK status: 503", 1)
+	tExpectIssue(t, hT, "Accepted status: 999", 1)
+	tExpectIssue(t, hT, "Accepted status: 401", 1)
+	// Two accepted warnings on top of the error
+	assert.Equals(t, "warnings", hT.issueStore.Count(issues.LevelWarning), 3)
+}
+
+func TestAcceptStatusCodesCached(t *testing.T) {
+	// accepted codes are served from the cache
+	var hits int32
+	server := tStatusServer(&hits)
+	defer server.Close()
+	tOpts := map[string]interface{}{
+		"EnableCache": true,
+		"OutputDir":   t.TempDir(),
+		"AcceptStatusCodes": map[interface{}]interface{}{
+			"/status/9": map[interface{}]interface{}{"Codes": 999},
+		},
+	}
+	tTestFileServer(t, "fixtures/links/statusCodes.html", server, tOpts)
+	assert.Equals(t, "first run hits", atomic.LoadInt32(&hits), int32(3))
+	hT := tTestFileServer(t, "fixtures/links/statusCodes.html", server, tOpts)
+	assert.Equals(t, "second run hits", atomic.LoadInt32(&hits), int32(5))
+	tExpectIssueCount(t, hT, 2)
+}
+
+func TestCacheExpiresClientError(t *testing.T) {
+	// broken links are served from the cache within CacheExpires*Error
+	var hits int32
+	server := tStatusServer(&hits)
+	defer server.Close()
+	tOpts := map[string]interface{}{
+		"EnableCache":             true,
+		"OutputDir":               t.TempDir(),
+		"CacheExpiresClientError": "1h",
+	}
+	tTestFileServer(t, "fixtures/links/statusCodes.html", server, tOpts)
+	assert.Equals(t, "first run hits", atomic.LoadInt32(&hits), int32(3))
+	hT := tTestFileServer(t, "fixtures/links/statusCodes.html", server, tOpts)
+	// Only the server error is fetched again
+	assert.Equals(t, "second run hits", atomic.LoadInt32(&hits), int32(4))
+	tExpectIssueCount(t, hT, 3)
+	tExpectIssue(t, hT, "Non-OK status: 999", 1)
+	tExpectIssue(t, hT, "from cache", 2)
+}
+
+func TestCacheExpiresNetworkError(t *testing.T) {
+	// connection failures are cached and reported again from the cache
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	tOpts := map[string]interface{}{
+		"EnableCache":              true,
+		"OutputDir":                t.TempDir(),
+		"CacheExpiresNetworkError": "1h",
+	}
+	hT := tTestFileServer(t, "fixtures/links/serverLink.html", server, tOpts)
+	tExpectIssueCount(t, hT, 1)
+	cR, ok := hT.refCache.Get(server.URL + "/page.html")
+	assert.IsTrue(t, "cached", ok)
+	assert.Equals(t, "outcome", cR.Outcome, refcache.OutcomeNetworkError)
+	assert.Equals(t, "error class", cR.ErrorClass, errorClassConnection)
+
+	hT = tTestFileServer(t, "fixtures/links/serverLink.html", server, tOpts)
+	tExpectIssueCount(t, hT, 1)
+	tExpectIssue(t, hT, "from cache", 1)
+	tExpectIssue(t, hT, "connection refused", 1)
+}
+
 func TestAnchorInternalBroken(t *testing.T) {
 	// fails for broken internal links
 	hT := tTestFile("fixtures/links/brokenLinkInternal.html")
@@ -682,6 +878,7 @@ func TestLinkHrefBrokenCanonicalOption(t *testing.T) {
 	hT := tTestFileOpts("fixtures/links/brokenCanonicalLink.html",
 		map[string]interface{}{"IgnoreCanonicalBrokenLinks": false, "VCREnable": true})
 	tExpectIssueCount(t, hT, 1)
+	tExpectIssue(t, hT, "Non-OK status: 404", 1)
 }
 
 func TestLinkRelDnsPrefetch(t *testing.T) {
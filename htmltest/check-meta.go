@@ -13,6 +13,9 @@ func (hT *HTMLTest) checkMeta(document *htmldoc.Document, node *html.Node) {
 	if hT.opts.CheckMetaRefresh {
 		hT.checkMetaRefresh(document, node)
 	}
+	if hT.opts.CheckOpenGraph {
+		hT.checkOpenGraph(document, node)
+	}
 }
 
 func (hT *HTMLTest) checkMetaRefresh(document *htmldoc.Document, node *html.Node) {
@@ -35,6 +38,7 @@ func (hT *HTMLTest) checkMetaRefresh(document *htmldoc.Document, node *html.Node
 				hT.issueStore.AddIssue(issues.Issue{
 					Level:    issues.LevelError,
 					Message:  "url in meta refresh must not start with single or double quote",
+					Rule:     issues.RuleMetaRefreshQuoted,
 					Document: document,
 				})
 				return
@@ -48,9 +52,11 @@ func (hT *HTMLTest) checkMetaRefresh(document *htmldoc.Document, node *html.Node
 				Level:    issues.LevelError,
 				Document: document,
 				Message:  fmt.Sprintf("bad reference: %q", err),
+				Rule:     issues.RuleRefInvalid,
 			})
 			return
 		}
+		ref.Attr = "content"
 
 		// If refresh the content attribute must be set
 		if htmldoc.AttrPresent(node.Attr, "content") {
@@ -60,6 +66,7 @@ func (hT *HTMLTest) checkMetaRefresh(document *htmldoc.Document, node *html.Node
 				hT.issueStore.AddIssue(issues.Issue{
 					Level:     issues.LevelError,
 					Message:   "blank content attribute in meta refresh",
+					Rule:      issues.RuleMetaRefreshBlank,
 					Reference: ref,
 				})
 				return // stop
@@ -71,6 +78,7 @@ func (hT *HTMLTest) checkMetaRefresh(document *htmldoc.Document, node *html.Node
 				hT.issueStore.AddIssue(issues.Issue{
 					Level:     issues.LevelError,
 					Message:   "invalid content attribute in meta refresh",
+					Rule:      issues.RuleMetaRefreshInvalid,
 					Reference: ref,
 				})
 			}
@@ -82,6 +90,7 @@ func (hT *HTMLTest) checkMetaRefresh(document *htmldoc.Document, node *html.Node
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:     issues.LevelError,
 				Message:   "missing content attribute in meta refresh",
+				Rule:      issues.RuleMetaRefreshMissing,
 				Reference: ref,
 			})
 		}
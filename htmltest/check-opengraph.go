@@ -0,0 +1,96 @@
+package htmltest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/issues"
+	"golang.org/x/net/html"
+)
+
+// OpenGraph properties every document must have
+var openGraphRequired = []string{"og:url", "og:image", "og:title", "og:type"}
+
+// OpenGraph and Twitter card properties holding URLs, which must be absolute
+var openGraphURLs = map[string]bool{
+	"og:url":        true,
+	"og:image":      true,
+	"twitter:image": true,
+}
+
+// Checks an OpenGraph or Twitter card meta tag. These are given by property,
+// though Twitter cards are often given by name.
+func (hT *HTMLTest) checkOpenGraph(document *htmldoc.Document, node *html.Node) {
+	property := strings.ToLower(htmldoc.GetAttr(node.Attr, "property"))
+	if property == "" {
+		property = strings.ToLower(htmldoc.GetAttr(node.Attr, "name"))
+	}
+	if !strings.HasPrefix(property, "og:") && !strings.HasPrefix(property, "twitter:") {
+		return
+	}
+
+	if document.State.OpenGraph == nil {
+		document.State.OpenGraph = make(map[string]bool)
+	}
+	document.State.OpenGraph[property] = true
+
+	// Only URLs are parsed, other content is free text
+	content := strings.TrimSpace(htmldoc.GetAttr(node.Attr, "content"))
+	refContent := ""
+	if openGraphURLs[property] {
+		refContent = content
+	}
+	ref, err := htmldoc.NewReference(document, node, refContent)
+	if err != nil {
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:    issues.LevelError,
+			Document: document,
+			Message:  fmt.Sprintf("bad reference: %q", err),
+			Rule:     issues.RuleRefInvalid,
+		})
+		return
+	}
+	ref.Attr = "content"
+
+	if content == "" {
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:     issues.LevelError,
+			Message:   fmt.Sprintf("%s content blank", property),
+			Rule:      issues.RuleOpenGraphEmpty,
+			Reference: ref,
+		})
+		return
+	}
+	if !openGraphURLs[property] {
+		return
+	}
+
+	// Absolute URLs under a BaseURL have been rebased to a site path
+	if ref.BaseURL == "" && (!ref.URL.IsAbs() || ref.URL.Host == "") {
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:     issues.LevelError,
+			Message:   fmt.Sprintf("%s is not an absolute URL", property),
+			Rule:      issues.RuleOpenGraphNotAbsolute,
+			Reference: ref,
+		})
+	}
+
+	// Check the reference
+	hT.checkGenericRef(ref)
+}
+
+// Checks the document has the required OpenGraph properties, after it's been
+// parsed.
+func (hT *HTMLTest) checkOpenGraphRequired(document *htmldoc.Document) {
+	for _, property := range openGraphRequired {
+		if !document.State.OpenGraph[property] {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:    issues.LevelError,
+				Document: document,
+				Message:  fmt.Sprintf("%s missing", property),
+				Rule:     issues.RuleOpenGraphMissing,
+			})
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package htmltest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func tTestOpenGraph(filename string) *HTMLTest {
+	return tTestFileOpts(filename, map[string]interface{}{"CheckOpenGraph": true})
+}
+
+func TestOpenGraphDisabled(t *testing.T) {
+	// doesn't check OpenGraph tags by default
+	hT := tTestFile("fixtures/opengraph/image-internal-broken.html")
+	tExpectIssueCount(t, hT, 0)
+}
+
+func TestOpenGraphMissing(t *testing.T) {
+	// requires og:url, og:image, og:title and og:type
+	hT := tTestOpenGraph("fixtures/opengraph/image-empty.html")
+	tExpectIssueCount(t, hT, 4)
+	tExpectIssue(t, hT, "og:url missing", 1)
+	tExpectIssue(t, hT, "og:title missing", 1)
+	tExpectIssue(t, hT, "og:type missing", 1)
+	tExpectIssue(t, hT, "og:image missing", 0)
+}
+
+func TestOpenGraphEmpty(t *testing.T) {
+	// fails for blank or missing content
+	for _, fixture := range []string{"image-empty.html", "image-missing.html"} {
+		hT := tTestOpenGraph("fixtures/opengraph/" + fixture)
+		tExpectIssue(t, hT, "og:image content blank", 1)
+	}
+	for _, fixture := range []string{"url-empty.html", "url-missing.html"} {
+		hT := tTestOpenGraph("fixtures/opengraph/" + fixture)
+		tExpectIssue(t, hT, "og:url content blank", 1)
+	}
+}
+
+func TestOpenGraphText(t *testing.T) {
+	// doesn't parse text content as a URL, only checks it isn't blank
+	hT := tTestOpenGraph("fixtures/opengraph/text.html")
+	tExpectIssueCount(t, hT, 3)
+	tExpectIssue(t, hT, "bad reference", 0)
+	tExpectIssue(t, hT, "twitter:title content blank", 1)
+}
+
+func TestOpenGraphImageRelative(t *testing.T) {
+	// fails for relative images, which are also checked
+	hT := tTestOpenGraph("fixtures/opengraph/image-internal-broken.html")
+	tExpectIssueCount(t, hT, 5)
+	tExpectIssue(t, hT, "og:image is not an absolute URL", 1)
+	tExpectIssue(t, hT, "target does not exist", 1)
+}
+
+func TestOpenGraphURLBroken(t *testing.T) {
+	// fails for an og:url which doesn't resolve
+	server := httptest.NewServer(http.HandlerFunc(http.NotFound))
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/opengraph/url-broken.html", server,
+		map[string]interface{}{"CheckOpenGraph": true})
+	tExpectIssueCount(t, hT, 4)
+	tExpectIssue(t, hT, "Non-OK status: 404", 1)
+	tExpectIssue(t, hT, "og:image missing", 1)
+	tExpectIssue(t, hT, "og:title missing", 1)
+	tExpectIssue(t, hT, "og:type missing", 1)
+}
+
+func TestOpenGraphComplete(t *testing.T) {
+	// checks external images, including Twitter card images given by name
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/opengraph/complete.html", server,
+		map[string]interface{}{"CheckOpenGraph": true})
+	tExpectIssueCount(t, hT, 1)
+	tExpectIssue(t, hT, "Non-OK status: 404", 1)
+}
+
+func TestOpenGraphBaseURL(t *testing.T) {
+	// checks absolute URLs under BaseURL internally
+	hT := tTestFileOpts("fixtures/opengraph/base-url.html", map[string]interface{}{
+		"CheckOpenGraph": true,
+		"BaseURL":        "https://www.example.com/",
+	})
+	tExpectIssueCount(t, hT, 1)
+	tExpectIssue(t, hT, "target does not exist", 1)
+}
@@ -19,9 +19,11 @@ func (hT *HTMLTest) checkScript(document *htmldoc.Document, node *html.Node) {
 			Level:    issues.LevelError,
 			Document: document,
 			Message:  fmt.Sprintf("bad reference: %q", err),
+			Rule:     issues.RuleRefInvalid,
 		})
 		return
 	}
+	ref.Attr = "src"
 
 	// Check src problems
 	if htmldoc.AttrPresent(node.Attr, "src") && len(attrs["src"]) == 0 {
@@ -29,6 +31,7 @@ func (hT *HTMLTest) checkScript(document *htmldoc.Document, node *html.Node) {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelError,
 			Message:   "src attribute present but empty",
+			Rule:      issues.RuleScriptSrcEmpty,
 			Reference: ref,
 		})
 		return
@@ -39,6 +42,7 @@ func (hT *HTMLTest) checkScript(document *htmldoc.Document, node *html.Node) {
 		hT.issueStore.AddIssue(issues.Issue{
 			Level:     issues.LevelError,
 			Message:   "script content missing / no src attribute",
+			Rule:      issues.RuleScriptContentMissing,
 			Reference: ref,
 		})
 		return
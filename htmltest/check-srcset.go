@@ -0,0 +1,87 @@
+package htmltest
+
+import (
+	"fmt"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/issues"
+	"golang.org/x/net/html"
+)
+
+// Attribute giving the sizes for width descriptors in each srcset attribute
+var srcsetSizesAttrs = map[string]string{
+	"srcset":      "sizes",
+	"imagesrcset": "imagesizes",
+}
+
+// Checks the image candidates in the provided node's srcset attribute key,
+// each candidate's URL is checked as a reference.
+func (hT *HTMLTest) checkSrcset(document *htmldoc.Document, node *html.Node, key string) {
+	// Fail silently if attribute isn't present
+	if !htmldoc.AttrPresent(node.Attr, key) {
+		return
+	}
+
+	candidates := htmldoc.ParseSrcset(htmldoc.GetAttr(node.Attr, key))
+	if len(candidates) == 0 {
+		ref, _ := htmldoc.NewReference(document, node, "")
+		ref.Attr = key
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:     issues.LevelError,
+			Message:   fmt.Sprintf("<%s> %s has no image candidates", node.Data, key),
+			Rule:      issues.RuleSrcsetEmpty,
+			Reference: ref,
+		})
+		return
+	}
+
+	var widthRef, densityRef *htmldoc.Reference
+	for _, candidate := range candidates {
+		ref, err := htmldoc.NewReference(document, node, candidate.URL)
+		if err != nil {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:    issues.LevelError,
+				Document: document,
+				Message:  fmt.Sprintf("bad reference: %q", err),
+				Rule:     issues.RuleRefInvalid,
+			})
+			continue
+		}
+		ref.Attr = key
+
+		if candidate.Invalid != "" {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:     issues.LevelError,
+				Message:   fmt.Sprintf("%s candidate: %s", key, candidate.Invalid),
+				Rule:      issues.RuleSrcsetInvalid,
+				Reference: ref,
+			})
+			continue
+		}
+		if candidate.Width > 0 {
+			widthRef = ref
+		} else {
+			// No descriptor is the same as 1x
+			densityRef = ref
+		}
+
+		hT.checkGenericRef(ref)
+	}
+
+	if widthRef != nil && densityRef != nil {
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:     issues.LevelError,
+			Message:   fmt.Sprintf("%s mixes width and density descriptors", key),
+			Rule:      issues.RuleSrcsetMixed,
+			Reference: densityRef,
+		})
+	}
+	if sizes := srcsetSizesAttrs[key]; widthRef != nil && !htmldoc.AttrPresent(node.Attr, sizes) {
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:     issues.LevelError,
+			Message:   fmt.Sprintf("%s has width descriptors but %s is missing", key, sizes),
+			Rule:      issues.RuleSrcsetSizesMissing,
+			Reference: widthRef,
+		})
+	}
+}
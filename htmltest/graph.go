@@ -0,0 +1,189 @@
+package htmltest
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/issues"
+)
+
+// Kinds of node in the site graph
+const (
+	graphNodeDocument string = "document" // Document in the store
+	graphNodeFile     string = "file"     // Other path in the site, such as an image or a missing target
+	graphNodeExternal string = "external" // External host, as scheme://host
+)
+
+// siteGraph : Links between documents, from internal references which resolve
+// to a document in the store, and every checked reference as an edge between
+// nodes. Built as documents are tested.
+type siteGraph struct {
+	mutex *sync.Mutex
+	links map[*htmldoc.Document][]*htmldoc.Document // Documents each links to, in the order first linked
+	seen  map[[2]*htmldoc.Document]bool
+	nodes map[string]string // Kind of each node, by ID
+	edges []graphEdge
+}
+
+// graphEdge : A reference from one node of the site graph to another
+type graphEdge struct {
+	from string
+	to   string
+	ref  *htmldoc.Reference
+}
+
+func newSiteGraph() *siteGraph {
+	return &siteGraph{
+		mutex: &sync.Mutex{},
+		links: make(map[*htmldoc.Document][]*htmldoc.Document),
+		seen:  make(map[[2]*htmldoc.Document]bool),
+		nodes: make(map[string]string),
+	}
+}
+
+// addEdge : Record a reference between two nodes of the given kinds, thread
+// safe.
+func (g *siteGraph) addEdge(from string, fromKind string, to string, toKind string, ref *htmldoc.Reference) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.nodes[from] = fromKind
+	g.nodes[to] = toKind
+	g.edges = append(g.edges, graphEdge{from, to, ref})
+}
+
+// add : Record a link between two documents, thread safe. Links to the same
+// document are left out.
+func (g *siteGraph) add(from *htmldoc.Document, to *htmldoc.Document) {
+	if from == to {
+		return
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if key := [2]*htmldoc.Document{from, to}; !g.seen[key] {
+		g.seen[key] = true
+		g.links[from] = append(g.links[from], to)
+	}
+}
+
+// depths : Click depth of every document reachable from the roots, found by a
+// breadth first walk of the links. Roots have a depth of zero.
+func (g *siteGraph) depths(roots []*htmldoc.Document) map[*htmldoc.Document]int {
+	depths := make(map[*htmldoc.Document]int)
+	queue := make([]*htmldoc.Document, 0, len(roots))
+	for _, root := range roots {
+		if _, ok := depths[root]; !ok {
+			depths[root] = 0
+			queue = append(queue, root)
+		}
+	}
+	for len(queue) > 0 {
+		doc := queue[0]
+		queue = queue[1:]
+		for _, to := range g.links[doc] {
+			if _, ok := depths[to]; !ok {
+				depths[to] = depths[doc] + 1
+				queue = append(queue, to)
+			}
+		}
+	}
+	return depths
+}
+
+// recordReference : Add ref to the site graph, if one is being built. target
+// is the document in the store ref resolves to, nil if it doesn't resolve to
+// one.
+func (hT *HTMLTest) recordReference(ref *htmldoc.Reference, target *htmldoc.Document) {
+	if hT.siteGraph == nil {
+		return
+	}
+
+	// Stylesheets stand in for documents but aren't in the store
+	fromKind := graphNodeDocument
+	if hT.documentStore.DocumentPathMap[ref.Document.SitePath] != ref.Document {
+		fromKind = graphNodeFile
+	}
+
+	switch {
+	case target != nil:
+		hT.siteGraph.add(ref.Document, target)
+		hT.siteGraph.addEdge(ref.Document.SitePath, fromKind, target.SitePath, graphNodeDocument, ref)
+	case ref.Scheme() == "file":
+		to := strings.TrimPrefix(path.Clean("/"+ref.RefSitePath()), "/")
+		hT.siteGraph.addEdge(ref.Document.SitePath, fromKind, to, graphNodeFile, ref)
+	default:
+		u, err := url.Parse(ref.URLString())
+		if err != nil || u.Host == "" {
+			return
+		}
+		to := u.Scheme + "://" + u.Host
+		hT.siteGraph.addEdge(ref.Document.SitePath, fromKind, to, graphNodeExternal, ref)
+	}
+}
+
+// The documents the site graph is walked from: the root's directory index and
+// the EntryPoints. Entry points not in the store are warned about.
+func (hT *HTMLTest) graphRoots() []*htmldoc.Document {
+	roots := make([]*htmldoc.Document, 0)
+	if root, ok := hT.documentStore.ResolvePath("/"); ok {
+		roots = append(roots, root)
+	} else {
+		hT.issueStore.AddIssue(issues.Issue{
+			Level:   issues.LevelWarning,
+			Message: "site root has no " + hT.opts.DirectoryIndex,
+		})
+	}
+	for _, item := range hT.opts.EntryPoints {
+		entry := fmt.Sprint(item)
+		if entry == "" {
+			continue
+		}
+		if doc, ok := hT.documentStore.ResolvePath(entry); ok {
+			roots = append(roots, doc)
+		} else {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:   issues.LevelWarning,
+				Message: fmt.Sprintf("entry point %q not found", entry),
+			})
+		}
+	}
+	return roots
+}
+
+// Checks every tested document can be reached by following links from the
+// site root or an entry point, and is within MaxClickDepth clicks of them.
+// Run once every document has been tested.
+func (hT *HTMLTest) checkOrphans() {
+	depths := hT.siteGraph.depths(hT.graphRoots())
+	for _, document := range hT.documentStore.Documents {
+		if document.IgnoreTest {
+			continue
+		}
+		depth, ok := depths[document]
+		switch {
+		case !ok:
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:    issues.LevelError,
+				Document: document,
+				Message:  "not linked from the site root",
+				Rule:     issues.RuleGraphOrphan,
+			})
+		case hT.opts.MaxClickDepth > 0 && depth > hT.opts.MaxClickDepth:
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:    issues.LevelError,
+				Document: document,
+				Message:  fmt.Sprintf("click depth %d exceeds MaxClickDepth %d", depth, hT.opts.MaxClickDepth),
+				Rule:     issues.RuleGraphTooDeep,
+			})
+		default:
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:    issues.LevelInfo,
+				Document: document,
+				Message:  fmt.Sprintf("click depth %d", depth),
+			})
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package htmltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/issues"
+	"github.com/wjdp/htmltest/output"
+)
+
+// graphReport : Layout of the JSON written by writeGraph
+type graphReport struct {
+	Nodes []graphNode     `json:"nodes"`
+	Edges []graphEdgeJSON `json:"edges"`
+}
+
+// graphNode : A document, other site path or external host. Degrees count
+// edges, so hub pages stand out.
+type graphNode struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	InDegree  int    `json:"inDegree"`
+	OutDegree int    `json:"outDegree"`
+}
+
+// graphEdgeJSON : A reference, broken if it has an error level issue
+type graphEdgeJSON struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Tag       string `json:"tag,omitempty"`
+	Attribute string `json:"attribute,omitempty"`
+	Reference string `json:"reference"`
+	Line      int    `json:"line,omitempty"`
+	Broken    bool   `json:"broken"`
+}
+
+// Build the report from the site graph, sorted so runs can be compared.
+// Edges are broken if their reference has an error which isn't in a baseline
+// or suppressed.
+func (hT *HTMLTest) graphReport() graphReport {
+	broken := make(map[*htmldoc.Reference]bool)
+	for _, issue := range hT.issueStore.Issues(issues.LevelError) {
+		if issue.Reference != nil && !issue.Baselined && !issue.Suppressed {
+			broken[issue.Reference] = true
+		}
+	}
+
+	g := hT.siteGraph
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	report := graphReport{
+		Nodes: make([]graphNode, 0, len(g.nodes)),
+		Edges: make([]graphEdgeJSON, 0, len(g.edges)),
+	}
+	index := make(map[string]int, len(g.nodes))
+	ids := make([]string, 0, len(g.nodes))
+	for id := range g.nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for i, id := range ids {
+		report.Nodes = append(report.Nodes, graphNode{ID: id, Kind: g.nodes[id]})
+		index[id] = i
+	}
+
+	for _, edge := range g.edges {
+		jE := graphEdgeJSON{
+			From:      edge.from,
+			To:        edge.to,
+			Attribute: edge.ref.Attr,
+			Reference: edge.ref.Path,
+			Line:      edge.ref.Line,
+			Broken:    broken[edge.ref],
+		}
+		if edge.ref.Node != nil {
+			jE.Tag = edge.ref.Node.Data
+		}
+		report.Edges = append(report.Edges, jE)
+		report.Nodes[index[edge.from]].OutDegree++
+		report.Nodes[index[edge.to]].InDegree++
+	}
+	sort.SliceStable(report.Edges, func(i, j int) bool {
+		a, b := report.Edges[i], report.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.To < b.To
+	})
+	return report
+}
+
+// writeGraph : Write the site graph to the given path, as Graphviz DOT if it
+// ends .dot or .gv, otherwise as JSON.
+func (hT *HTMLTest) writeGraph(path string) {
+	report := hT.graphReport()
+
+	os.MkdirAll(filepath.Dir(path), 0777)
+	f, err := os.Create(path)
+	output.CheckErrorPanic(err)
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dot", ".gv":
+		err = report.writeDOT(f)
+	default:
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(&report)
+	}
+	output.CheckErrorPanic(err)
+}
+
+// Shape of each kind of node in DOT output
+var graphDOTShapes = map[string]string{
+	graphNodeDocument: "box",
+	graphNodeFile:     "note",
+	graphNodeExternal: "ellipse",
+}
+
+// Write the report as a Graphviz digraph. Edges are labelled with their tag
+// and attribute, broken ones are red.
+func (report *graphReport) writeDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph htmltest {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, node := range report.Nodes {
+		fmt.Fprintf(&b, "  %s [shape=%s];\n", dotQuote(node.ID), graphDOTShapes[node.Kind])
+	}
+	for _, edge := range report.Edges {
+		label := strings.TrimSpace(edge.Tag + " " + edge.Attribute)
+		attrs := "label=" + dotQuote(label)
+		if edge.Broken {
+			attrs += ", color=red, fontcolor=red, style=bold"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n", dotQuote(edge.From), dotQuote(edge.To), attrs)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Quote s as a DOT ID
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
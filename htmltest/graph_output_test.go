@@ -0,0 +1,83 @@
+package htmltest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+)
+
+func tTestLinkGraph(t *testing.T, filename string, tOpts map[string]interface{}) string {
+	graphFile := path.Join(t.TempDir(), filename)
+	opts := map[string]interface{}{
+		"CheckExternal": false,
+		"GraphFile":     graphFile,
+	}
+	for key, value := range tOpts {
+		opts[key] = value
+	}
+	tTestDirectoryOpts("fixtures/linkgraph", opts)
+	b, err := ioutil.ReadFile(graphFile)
+	assert.NoErrorf(t, "read graph", err)
+	return string(b)
+}
+
+func TestGraphJSON(t *testing.T) {
+	// writes documents, files and hosts with each reference between them
+	var report graphReport
+	err := json.Unmarshal([]byte(tTestLinkGraph(t, "graph.json", nil)), &report)
+	assert.NoErrorf(t, "decode graph", err)
+
+	assert.StringEquals(t, "nodes", report.Nodes, []graphNode{
+		{ID: "about.html", Kind: "document", InDegree: 1, OutDegree: 3},
+		{ID: "http://www.example.com", Kind: "external", InDegree: 1},
+		{ID: "https://www.example.com", Kind: "external", InDegree: 1},
+		{ID: "index.html", Kind: "document", InDegree: 1, OutDegree: 3},
+		{ID: "logo.png", Kind: "file", InDegree: 1},
+		{ID: "missing.html", Kind: "file", InDegree: 1},
+	})
+	assert.Equals(t, "edges", len(report.Edges), 6)
+	assert.StringEquals(t, "broken edge", report.Edges[1], graphEdgeJSON{
+		From: "about.html", To: "missing.html", Tag: "a", Attribute: "href",
+		Reference: "missing.html", Line: 5, Broken: true,
+	})
+	assert.StringEquals(t, "image edge", report.Edges[4], graphEdgeJSON{
+		From: "index.html", To: "logo.png", Tag: "img", Attribute: "src",
+		Reference: "logo.png", Line: 5,
+	})
+}
+
+func TestGraphUnchecked(t *testing.T) {
+	// has edges for references which aren't checked, none of them broken
+	for _, opts := range []map[string]interface{}{
+		{"CheckInternal": false},
+		{"IgnoreInternalURLs": []interface{}{"about.html", "missing.html"}},
+	} {
+		var report graphReport
+		err := json.Unmarshal([]byte(tTestLinkGraph(t, "graph.json", opts)), &report)
+		assert.NoErrorf(t, "decode graph", err)
+		assert.Equals(t, "edges", len(report.Edges), 6)
+		assert.StringEquals(t, "unchecked edge", report.Edges[1], graphEdgeJSON{
+			From: "about.html", To: "missing.html", Tag: "a", Attribute: "href",
+			Reference: "missing.html", Line: 5,
+		})
+	}
+}
+
+func TestGraphDOT(t *testing.T) {
+	// writes a Graphviz digraph with broken edges in red
+	dot := tTestLinkGraph(t, "graph.dot", nil)
+	assert.IsTrue(t, "digraph", strings.HasPrefix(dot, "digraph htmltest {\n"))
+	assert.IsTrue(t, "document node", strings.Contains(dot, `  "index.html" [shape=box];`))
+	assert.IsTrue(t, "host node", strings.Contains(dot, `  "https://www.example.com" [shape=ellipse];`))
+	assert.IsTrue(t, "edge", strings.Contains(dot, `  "index.html" -> "about.html" [label="a href"];`))
+	assert.IsTrue(t, "broken edge", strings.Contains(dot,
+		`  "about.html" -> "missing.html" [label="a href", color=red, fontcolor=red, style=bold];`))
+}
+
+func TestDotQuote(t *testing.T) {
+	assert.Equals(t, "quoted", dotQuote(`a "b" \c`), `"a \"b\" \\c"`)
+}
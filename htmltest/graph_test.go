@@ -0,0 +1,95 @@
+package htmltest
+
+import (
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/issues"
+)
+
+func TestSiteGraphDepths(t *testing.T) {
+	// walks links breadth first from the roots
+	docs := make([]*htmldoc.Document, 5)
+	for i := range docs {
+		docs[i] = &htmldoc.Document{}
+	}
+	g := newSiteGraph()
+	g.add(docs[0], docs[1])
+	g.add(docs[1], docs[2])
+	g.add(docs[0], docs[2])
+	g.add(docs[2], docs[0])
+	g.add(docs[3], docs[3])
+	depths := g.depths(docs[:1])
+	assert.Equals(t, "root", depths[docs[0]], 0)
+	assert.Equals(t, "linked from root", depths[docs[1]], 1)
+	assert.Equals(t, "shortest path", depths[docs[2]], 1)
+	_, ok := depths[docs[3]]
+	assert.IsFalse(t, "self linked", ok)
+	_, ok = depths[docs[4]]
+	assert.IsFalse(t, "unlinked", ok)
+	assert.Equals(t, "links deduplicated", len(g.links[docs[0]]), 2)
+}
+
+func tTestGraph(tOpts map[string]interface{}) *HTMLTest {
+	opts := map[string]interface{}{
+		"CheckOrphans": true,
+		"IgnoreDirs":   []interface{}{"ignored"},
+	}
+	for key, value := range tOpts {
+		opts[key] = value
+	}
+	return tTestDirectoryOpts("fixtures/graph", opts)
+}
+
+func TestOrphansDisabled(t *testing.T) {
+	// doesn't look for orphans by default
+	hT := tTestDirectory("fixtures/graph")
+	tExpectIssueCount(t, hT, 0)
+}
+
+func TestOrphans(t *testing.T) {
+	// reports documents not reached from the root, except ignored ones
+	hT := tTestGraph(nil)
+	tExpectIssueCount(t, hT, 3)
+	tExpectIssue(t, hT, "not linked from the site root", 3)
+	orphan, _ := hT.documentStore.ResolvePath("orphan.html")
+	assert.Equals(t, "orphan.html", hT.issueStore.CountByDoc(issues.LevelError, orphan), 1)
+	tExpectIssue(t, hT, "click depth 0", 1)
+	tExpectIssue(t, hT, "click depth 1", 1)
+	tExpectIssue(t, hT, "click depth 2", 1)
+	tExpectIssue(t, hT, "click depth 3", 1)
+}
+
+func TestOrphansEntryPoints(t *testing.T) {
+	// entry points are walked from too
+	hT := tTestGraph(map[string]interface{}{
+		"EntryPoints": []interface{}{"/entry.html", "missing.html"},
+	})
+	tExpectIssueCount(t, hT, 1)
+	tExpectIssue(t, hT, "not linked from the site root", 1)
+	tExpectIssue(t, hT, `entry point "missing.html" not found`, 1)
+}
+
+func TestOrphansMaxClickDepth(t *testing.T) {
+	// reports documents too many clicks from the root
+	hT := tTestGraph(map[string]interface{}{
+		"EntryPoints":   "entry.html",
+		"MaxClickDepth": 2,
+	})
+	tExpectIssueCount(t, hT, 2)
+	tExpectIssue(t, hT, "click depth 3 exceeds MaxClickDepth 2", 1)
+}
+
+func TestOrphansUnchecked(t *testing.T) {
+	// links are followed even if they aren't checked
+	for _, opts := range []map[string]interface{}{
+		{"CheckInternal": false},
+		{"IgnoreInternalURLs": []interface{}{"a.html", "deep/b.html"}},
+	} {
+		hT := tTestGraph(opts)
+		tExpectIssueCount(t, hT, 3)
+		tExpectIssue(t, hT, "not linked from the site root", 3)
+		tExpectIssue(t, hT, "click depth 3", 1)
+	}
+}
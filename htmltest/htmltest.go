@@ -26,12 +26,19 @@ const vcrCassetteBasePath string = "fixtures/vcr"
 // HTMLTest struct, A html testing session, user options are passed in and
 // tests are run.
 type HTMLTest struct {
-	opts          Options
-	httpClient    *http.Client
-	httpChannel   chan bool
-	documentStore htmldoc.DocumentStore
-	issueStore    issues.IssueStore
-	refCache      *refcache.RefCache
+	opts             Options
+	httpClient       *http.Client
+	httpScheduler    *httpScheduler
+	externalRequests *requestGroup
+	pipeline         *pipeline
+	documentStore    htmldoc.DocumentStore
+	issueStore       issues.IssueStore
+	refCache         *refcache.RefCache
+	stylesheets      *stylesheetSet
+	siteGraph        *siteGraph
+	retryWait        time.Duration // ExternalRetryWait, parsed
+	retryMaxWait     time.Duration // ExternalRetryMaxWait, parsed
+	acceptRules      []acceptRule  // AcceptStatusCodes, compiled
 }
 
 func setRedirectLimitCheck(hT HTMLTest) func(req *http.Request, via []*http.Request) error {
@@ -48,6 +55,7 @@ func setRedirectLimitCheck(hT HTMLTest) func(req *http.Request, via []*http.Requ
 			hT.issueStore.AddIssue(issues.Issue{
 				Level:   issues.LevelError,
 				Message: "too many redirects: " + originalURL,
+				Rule:    issues.RuleExternalRedirectsLimit,
 			})
 			return errors.New("too many redirects: " + originalURL)
 		}
@@ -66,11 +74,14 @@ func Test(optsUser map[string]interface{}) (*HTMLTest, error) {
 	}
 
 	// Merge user options with defaults and set hT.opts
-	hT.setOptions(optsUser)
+	if err := hT.setOptions(optsUser); err != nil {
+		return &hT, err
+	}
 
 	// Create issue store and set LogLevel and printImmediately if sort is seq
 	hT.issueStore = issues.NewIssueStore(hT.opts.LogLevel,
 		(hT.opts.LogSort == "seq"))
+	hT.issueStore.SetRuleLevels(hT.opts.ruleLevels())
 
 	transport := &http.Transport{
 		// Disable HTTP/2, this is required due to a number of edge cases where http negotiates H2, but something goes
@@ -104,8 +115,19 @@ func Test(optsUser map[string]interface{}) (*HTMLTest, error) {
 		hT.httpClient = vcr.Client
 	}
 
-	// Make buffered channel to act as concurrency limiter
-	hT.httpChannel = make(chan bool, hT.opts.HTTPConcurrencyLimit)
+	// Setup scheduler to limit external requests, globally and per host
+	defaultHostLimit, hostLimits, err := hT.opts.hostLimits()
+	if err != nil {
+		return &hT, err
+	}
+	hT.httpScheduler = newHTTPScheduler(hT.opts.HTTPConcurrencyLimit,
+		defaultHostLimit, hostLimits)
+
+	hT.externalRequests = newRequestGroup()
+	hT.stylesheets = newStylesheetSet()
+	if hT.opts.CheckOrphans || hT.opts.GraphFile != "" {
+		hT.siteGraph = newSiteGraph()
+	}
 
 	// Setup refCache
 	cachePath := ""
@@ -113,6 +135,9 @@ func Test(optsUser map[string]interface{}) (*HTMLTest, error) {
 		cachePath = path.Join(hT.opts.OutputDir, hT.opts.OutputCacheFile)
 	}
 	hT.refCache = refcache.NewRefCache(cachePath, hT.opts.CacheExpires)
+	hT.refCache.SetExpires(refcache.OutcomeClientError, hT.opts.CacheExpiresClientError)
+	hT.refCache.SetExpires(refcache.OutcomeServerError, hT.opts.CacheExpiresServerError)
+	hT.refCache.SetExpires(refcache.OutcomeNetworkError, hT.opts.CacheExpiresNetworkError)
 
 	if hT.opts.NoRun {
 		return &hT, nil
@@ -141,6 +166,18 @@ func Test(optsUser map[string]interface{}) (*HTMLTest, error) {
 		return &hT, err
 	}
 
+	if hT.opts.WriteBaseline && hT.opts.BaselineFile == "" {
+		err := errors.New("WriteBaseline needs a BaselineFile to write to")
+		return &hT, err
+	}
+	if hT.opts.BaselineFile != "" && !hT.opts.WriteBaseline {
+		if err := hT.issueStore.LoadBaseline(hT.opts.BaselineFile); err != nil {
+			err := errors.New(fmt.Sprint(
+				"Cannot read baseline '", hT.opts.BaselineFile, "': ", err))
+			return &hT, err
+		}
+	}
+
 	// Init our document store
 	hT.documentStore = htmldoc.NewDocumentStore()
 	// Setup document store
@@ -149,6 +186,8 @@ func Test(optsUser map[string]interface{}) (*HTMLTest, error) {
 	hT.documentStore.DirectoryIndex = hT.opts.DirectoryIndex
 	hT.documentStore.IgnorePatterns = hT.opts.IgnoreDirs
 	hT.documentStore.IgnoreTagAttribute = hT.opts.IgnoreTagAttribute
+	hT.documentStore.BaseURLs = hT.opts.BaseURL
+	hT.documentStore.StyleAttributes = hT.opts.CheckCSS
 	// Discover documents
 	hT.documentStore.Discover()
 
@@ -161,10 +200,14 @@ func Test(optsUser map[string]interface{}) (*HTMLTest, error) {
 			return &hT, err
 		}
 		hT.testDocument(doc)
+		hT.printDocumentIssues(doc)
 	} else if hT.opts.DirectoryPath != "" {
 		// Test documents
 		hT.testDocuments()
 	}
+	for _, sheet := range hT.stylesheets.sheets {
+		hT.printDocumentIssues(sheet)
+	}
 
 	if hT.opts.EnableCache {
 		hT.refCache.WriteStore(cachePath)
@@ -173,6 +216,24 @@ func Test(optsUser map[string]interface{}) (*HTMLTest, error) {
 		hT.issueStore.WriteLog(path.Join(hT.opts.OutputDir,
 			hT.opts.OutputLogFile))
 	}
+	if hT.opts.EnableJSON {
+		hT.issueStore.WriteJSON(path.Join(hT.opts.OutputDir,
+			hT.opts.OutputJSONFile), len(hT.testedDocuments()))
+	}
+	if hT.opts.EnableJUnit {
+		hT.issueStore.WriteJUnit(path.Join(hT.opts.OutputDir,
+			hT.opts.OutputJUnitFile), hT.checkedDocuments())
+	}
+	if hT.opts.EnableSARIF {
+		hT.issueStore.WriteSARIF(path.Join(hT.opts.OutputDir,
+			hT.opts.OutputSARIFFile), hT.opts.Version)
+	}
+	if hT.opts.WriteBaseline {
+		hT.issueStore.WriteBaseline(hT.opts.BaselineFile)
+	}
+	if hT.opts.GraphFile != "" {
+		hT.writeGraph(hT.opts.GraphFile)
+	}
 
 	// This is useful for debugging the VCR, but rather noisy otherwise
 	//if hT.opts.VCREnable {
@@ -183,29 +244,56 @@ func Test(optsUser map[string]interface{}) (*HTMLTest, error) {
 }
 
 func (hT *HTMLTest) testDocuments() {
-	if hT.opts.TestFilesConcurrently {
-		hT.issueStore.AddIssue(issues.Issue{
-			Level:   issues.LevelWarning,
-			Message: "running in concurrent mode, this is experimental",
-		})
-		var wg sync.WaitGroup
-		// Make buffered channel to act as concurrency limiter
-		var concChannel = make(chan bool, hT.opts.DocumentConcurrencyLimit)
+	if hT.opts.TestFilesPipeline {
+		hT.testDocumentsPipeline()
 		for _, document := range hT.documentStore.Documents {
-			wg.Add(1)
-			concChannel <- true // Add to concurrency limiter
-			go func(document *htmldoc.Document) {
-				defer wg.Done()
-				hT.testDocument(document)
-				<-concChannel // Bump off concurrency limiter
-			}(document)
+			hT.printDocumentIssues(document)
 		}
-		wg.Wait()
 	} else {
-		for _, document := range hT.documentStore.Documents {
+		hT.forEachDocument(func(document *htmldoc.Document) {
 			hT.testDocument(document)
+			hT.printDocumentIssues(document)
+		})
+	}
+
+	// Checks across documents, once every document has been tested. Their
+	// issues are printed straight away, each document's have been already.
+	hT.issueStore.SetPrintImmediately(true)
+	if hT.opts.CheckDuplicates {
+		hT.checkDuplicates()
+	}
+	if hT.opts.CheckOrphans {
+		hT.checkOrphans()
+	}
+}
+
+// forEachDocument : Call fn with each document in the store, in turn or, with
+// TestFilesConcurrently, up to DocumentConcurrencyLimit at a time.
+func (hT *HTMLTest) forEachDocument(fn func(document *htmldoc.Document)) {
+	if !hT.opts.TestFilesConcurrently {
+		for _, document := range hT.documentStore.Documents {
+			fn(document)
 		}
+		return
+	}
+
+	hT.issueStore.AddIssue(issues.Issue{
+		Level:   issues.LevelWarning,
+		Message: "running in concurrent mode, this is experimental",
+	})
+	var wg sync.WaitGroup
+	// Make buffered channel to act as concurrency limiter
+	var concChannel = make(chan bool, hT.opts.DocumentConcurrencyLimit)
+	for _, document := range hT.documentStore.Documents {
+		wg.Add(1)
+		concChannel <- true // Add to concurrency limiter
+		go func(document *htmldoc.Document) {
+			defer wg.Done()
+			fn(document)
+			<-concChannel // Bump off concurrency limiter
+		}(document)
 	}
+	wg.Wait()
 }
 
 func (hT *HTMLTest) testDocument(document *htmldoc.Document) {
@@ -229,6 +317,9 @@ func (hT *HTMLTest) testDocument(document *htmldoc.Document) {
 	}
 
 	for _, n := range document.NodesOfInterest {
+		if hT.opts.CheckCSS && htmldoc.AttrPresent(n.Attr, "style") {
+			hT.checkStyleAttr(document, n)
+		}
 		switch n.Data {
 		case "a":
 			if hT.opts.CheckAnchors {
@@ -258,10 +349,15 @@ func (hT *HTMLTest) testDocument(document *htmldoc.Document) {
 			if hT.opts.CheckGeneric {
 				hT.checkGeneric(document, n, "cite")
 			}
-		case "iframe", "input", "audio", "embed", "source", "track":
+		case "iframe", "input", "audio", "embed", "track":
 			if hT.opts.CheckGeneric {
 				hT.checkGeneric(document, n, "src")
 			}
+		case "source":
+			if hT.opts.CheckGeneric {
+				hT.checkGeneric(document, n, "src")
+				hT.checkSrcset(document, n, "srcset")
+			}
 		case "video":
 			if hT.opts.CheckGeneric {
 				hT.checkGeneric(document, n, "src")
@@ -271,12 +367,18 @@ func (hT *HTMLTest) testDocument(document *htmldoc.Document) {
 			if hT.opts.CheckGeneric {
 				hT.checkGeneric(document, n, "data")
 			}
+		case "style":
+			if hT.opts.CheckCSS {
+				hT.checkStyleElement(document, n)
+			}
 		}
 	}
 	hT.postChecks(document)
+}
 
-	// If sorting by document output issues now
-	if hT.opts.LogSort == "document" {
+// If sorting by document output the issues of a tested document now
+func (hT *HTMLTest) printDocumentIssues(document *htmldoc.Document) {
+	if hT.opts.LogSort == "document" && !document.IgnoreTest {
 		hT.issueStore.PrintDocumentIssues(document)
 	}
 }
@@ -285,10 +387,24 @@ func (hT *HTMLTest) postChecks(document *htmldoc.Document) {
 	// Checks to run after document has been parsed
 	if hT.opts.CheckFavicon && !document.State.FaviconPresent {
 		hT.issueStore.AddIssue(issues.Issue{
-			Level:   issues.LevelError,
-			Message: "favicon missing",
+			Level:    issues.LevelError,
+			Document: document,
+			Message:  "favicon missing",
+			Rule:     issues.RuleFaviconMissing,
 		})
 	}
+	if hT.opts.CheckOpenGraph {
+		hT.checkOpenGraphRequired(document)
+	}
+	if hT.opts.CheckHead {
+		hT.checkHead(document)
+	}
+	if hT.opts.CheckDuplicates {
+		hT.recordDuplicates(document)
+	}
+	if hT.opts.CheckHreflang {
+		hT.checkHreflang(document)
+	}
 }
 
 // CountErrors : Return number of error level issues
@@ -296,7 +412,42 @@ func (hT *HTMLTest) CountErrors() int {
 	return hT.issueStore.Count(issues.LevelError)
 }
 
+// CountBaselined : Return number of errors found which are in the baseline
+func (hT *HTMLTest) CountBaselined() int {
+	return hT.issueStore.CountBaselined()
+}
+
+// CountSuppressed : Return number of issues turned off by comments in their
+// documents
+func (hT *HTMLTest) CountSuppressed() int {
+	return hT.issueStore.CountSuppressed()
+}
+
+// BaselineFixed : Return the baseline entries for the tested documents which
+// no longer occur
+func (hT *HTMLTest) BaselineFixed() []issues.BaselineEntry {
+	return hT.issueStore.BaselineFixed(hT.checkedDocuments())
+}
+
 // CountDocuments : Return number of documents in hT document store
 func (hT *HTMLTest) CountDocuments() int {
 	return len(hT.documentStore.Documents)
 }
+
+// The documents this run tested, just the one given in single document mode
+func (hT *HTMLTest) testedDocuments() []*htmldoc.Document {
+	if hT.opts.FilePath != "" {
+		if doc, ok := hT.documentStore.ResolvePath(hT.opts.FilePath); ok {
+			return []*htmldoc.Document{doc}
+		}
+	}
+	return hT.documentStore.Documents
+}
+
+// The tested documents along with the local stylesheets they link
+func (hT *HTMLTest) checkedDocuments() []*htmldoc.Document {
+	tested := hT.testedDocuments()
+	documents := make([]*htmldoc.Document, 0, len(tested)+len(hT.stylesheets.sheets))
+	documents = append(documents, tested...)
+	return append(documents, hT.stylesheets.sheets...)
+}
@@ -1,9 +1,14 @@
 package htmltest
 
 import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"path"
 	"testing"
 
 	"github.com/daviddengcn/go-assert"
+	"github.com/wjdp/htmltest/issues"
 )
 
 func TestMissingOptions(t *testing.T) {
@@ -154,3 +159,178 @@ func TestRedirectLimitExceeded(t *testing.T) {
 		map[string]interface{}{"RedirectLimit": 0})
 	tExpectIssueCount(t, hT, 1)
 }
+
+func TestEnableJSON(t *testing.T) {
+	// writes a JSON report with the structured details of issues
+	var hits int32
+	server := tStatusServer(&hits)
+	defer server.Close()
+	outputDir := t.TempDir()
+	hT := tTestFileServer(t, "fixtures/links/statusCodes.html", server,
+		map[string]interface{}{
+			"EnableJSON": true,
+			"OutputDir":  outputDir,
+			"LogLevel":   issues.LevelError, // Report is filtered by LogLevel
+		})
+	tExpectIssueCount(t, hT, 3)
+
+	b, err := ioutil.ReadFile(path.Join(outputDir, "htmltest.json"))
+	assert.NoErrorf(t, "read report", err)
+	var report struct {
+		Totals struct {
+			Documents int
+			Errors    int
+			Passed    bool
+		}
+		Issues []struct {
+			Document  string
+			Line      int
+			Column    int
+			Tag       string
+			Attribute string
+			URL       string
+			Status    int
+		}
+	}
+	assert.NoErrorf(t, "parse report", json.Unmarshal(b, &report))
+	assert.Equals(t, "documents", report.Totals.Documents, 1)
+	assert.Equals(t, "errors", report.Totals.Errors, 3)
+	assert.IsFalse(t, "passed", report.Totals.Passed)
+	assert.Equals(t, "issues", len(report.Issues), 3)
+	assert.Equals(t, "document", report.Issues[0].Document, "statusCodes.html")
+	assert.Equals(t, "line", report.Issues[1].Line, 2)
+	assert.Equals(t, "column", report.Issues[1].Column, 1)
+	assert.Equals(t, "tag", report.Issues[0].Tag, "a")
+	assert.Equals(t, "attribute", report.Issues[0].Attribute, "href")
+	assert.Equals(t, "url", report.Issues[0].URL, server.URL+"/status/999")
+	assert.Equals(t, "status", report.Issues[0].Status, 999)
+}
+
+func TestEnableJUnit(t *testing.T) {
+	// writes a JUnit report with a testcase per document
+	outputDir := t.TempDir()
+	tTestDirectoryOpts("fixtures/documents/folder-not-ok", map[string]interface{}{
+		"EnableJUnit": true,
+		"OutputDir":   outputDir,
+		"IgnoreDirs":  []interface{}{"a"},
+	})
+	b, err := ioutil.ReadFile(path.Join(outputDir, "htmltest.xml"))
+	assert.NoErrorf(t, "read report", err)
+	var report struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Skipped  int `xml:"skipped,attr"`
+		Suites   []struct {
+			Cases []struct {
+				Name     string    `xml:"name,attr"`
+				Skipped  *struct{} `xml:"skipped"`
+				Failures []struct {
+					Message string `xml:"message,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	assert.NoErrorf(t, "parse report", xml.Unmarshal(b, &report))
+	assert.Equals(t, "tests", report.Tests, 3)
+	assert.Equals(t, "failures", report.Failures, 1)
+	assert.Equals(t, "skipped", report.Skipped, 1)
+	for _, testCase := range report.Suites[0].Cases {
+		switch testCase.Name {
+		case "index2.html":
+			assert.Equals(t, "index2.html failures", len(testCase.Failures), 1)
+			assert.Equals(t, "index2.html failure", testCase.Failures[0].Message, "target does not exist")
+		case "a/sub.html":
+			assert.IsTrue(t, "a/sub.html skipped", testCase.Skipped != nil)
+		default:
+			assert.Equals(t, testCase.Name+" failures", len(testCase.Failures), 0)
+		}
+	}
+}
+
+func TestEnableSARIF(t *testing.T) {
+	// writes a SARIF report locating issues in their source files
+	outputDir := t.TempDir()
+	tTestDirectoryOpts("fixtures/documents/folder-not-ok", map[string]interface{}{
+		"EnableSARIF": true,
+		"OutputDir":   outputDir,
+		"LogLevel":    issues.LevelError, // Report is filtered by LogLevel
+	})
+	b, err := ioutil.ReadFile(path.Join(outputDir, "htmltest.sarif"))
+	assert.NoErrorf(t, "read report", err)
+	var report struct {
+		Runs []struct {
+			Results []struct {
+				RuleID    string
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+						Region           struct{ StartLine, StartColumn int }
+					}
+				}
+			}
+		}
+	}
+	assert.NoErrorf(t, "parse report", json.Unmarshal(b, &report))
+	results := report.Runs[0].Results
+	assert.Equals(t, "results", len(results), 2)
+	for _, result := range results {
+		location := result.Locations[0].PhysicalLocation
+		switch location.ArtifactLocation.URI {
+		case "fixtures/documents/folder-not-ok/index2.html":
+			assert.Equals(t, "index2.html rule", result.RuleID, issues.RuleLinkTargetMissing)
+			assert.Equals(t, "index2.html line", location.Region.StartLine, 8)
+			assert.Equals(t, "index2.html column", location.Region.StartColumn, 1)
+		case "fixtures/documents/folder-not-ok/a/sub.html":
+			assert.Equals(t, "a/sub.html rule", result.RuleID, issues.RuleLinkDirectoryNoIndex)
+			assert.Equals(t, "a/sub.html line", location.Region.StartLine, 8)
+		default:
+			t.Error("unexpected location", location.ArtifactLocation.URI)
+		}
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	// known errors in the baseline don't count, new ones and fixed ones do
+	baselinePath := path.Join(t.TempDir(), "baseline.json")
+	hT := tTestFileOpts("fixtures/links/brokenLinkInternal.html",
+		map[string]interface{}{"BaselineFile": baselinePath, "WriteBaseline": true})
+	tExpectIssueCount(t, hT, 1)
+
+	hT = tTestFileOpts("fixtures/links/brokenLinkInternal.html",
+		map[string]interface{}{"BaselineFile": baselinePath})
+	tExpectIssueCount(t, hT, 0)
+	assert.Equals(t, "baselined", hT.CountBaselined(), 1)
+	assert.Equals(t, "fixed", len(hT.BaselineFixed()), 0)
+
+	hT = tTestFileOpts("fixtures/links/brokenLinkFile.html",
+		map[string]interface{}{"BaselineFile": baselinePath})
+	assert.Equals(t, "baselined", hT.CountBaselined(), 0)
+	assert.Equals(t, "fixed in other documents", len(hT.BaselineFixed()), 0)
+}
+
+func TestBaselineWriteWithoutFile(t *testing.T) {
+	_, err := Test(map[string]interface{}{
+		"DirectoryPath": "fixtures/links",
+		"WriteBaseline": true,
+	})
+	assert.NotEquals(t, "Error", err, nil)
+}
+
+func TestSuppressionComments(t *testing.T) {
+	// issues turned off by comments don't count, but are totalled
+	hT := tTestFileOpts("fixtures/suppress/comments.html",
+		map[string]interface{}{"CheckFavicon": true})
+	tExpectIssueCount(t, hT, 2)
+	tExpectIssue(t, hT, "alt attribute missing", 2)
+	tExpectIssue(t, hT, "target does not exist", 2)
+	assert.Equals(t, "suppressed", hT.CountSuppressed(), 3)
+}
+
+func TestBaseURL(t *testing.T) {
+	// absolute URLs under BaseURL are checked against the site, not fetched
+	hT := tTestDirectoryOpts("fixtures/baseURL",
+		map[string]interface{}{"BaseURL": "https://www.example.com"})
+	tExpectIssueCount(t, hT, 3)
+	tExpectIssue(t, hT, "hash does not exist", 1)
+	tExpectIssue(t, hT, "target does not exist", 2)
+}
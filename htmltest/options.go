@@ -5,7 +5,10 @@ import (
 	"path"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/imdario/mergo"
 	"github.com/wjdp/htmltest/issues"
@@ -18,6 +21,7 @@ type Options struct {
 	DirectoryIndex string
 	FilePath       string
 	FileExtension  string
+	BaseURL        []interface{} // URLs the site is published at, links under them are checked internally
 
 	CheckDoctype bool
 	CheckAnchors bool
@@ -26,18 +30,31 @@ type Options struct {
 	CheckScripts bool
 	CheckMeta    bool
 	CheckGeneric bool
+	CheckCSS     bool
 
 	CheckExternal     bool
 	CheckInternal     bool
 	CheckInternalHash bool
+	CheckExternalHash bool
 	CheckMailto       bool
 	CheckTel          bool
 	CheckFavicon      bool
 	CheckMetaRefresh  bool
+	CheckOpenGraph    bool
+	CheckHead         bool
+	CheckDuplicates   bool
+	CheckHreflang     bool
+	CheckOrphans      bool
 
 	EnforceHTML5 bool
 	EnforceHTTPS bool
 
+	HeadDescriptionMinLength int // Fewest characters CheckHead allows in a meta description
+	HeadDescriptionMaxLength int // Most characters CheckHead allows in a meta description
+
+	EntryPoints   []interface{} // Pages CheckOrphans starts from as well as the site root
+	MaxClickDepth int           // Most clicks CheckOrphans allows from the site root to a page, 0 for no limit
+
 	IgnoreURLs         []interface{}
 	IgnoreInternalURLs []interface{}
 	IgnoreHTTPS        []interface{}
@@ -53,27 +70,55 @@ type Options struct {
 	IgnoreSSLVerify                     bool
 	IgnoreTagAttribute                  string
 
-	HTTPHeaders map[interface{}]interface{}
+	Rules map[interface{}]interface{}
+
+	HTTPHeaders    map[interface{}]interface{}
+	HTTPMethod     string
+	HTTPMethodURLs map[interface{}]interface{}
 
-	TestFilesConcurrently    bool
-	DocumentConcurrencyLimit int
-	HTTPConcurrencyLimit     int
+	TestFilesConcurrently     bool
+	TestFilesPipeline         bool
+	DocumentConcurrencyLimit  int
+	HTTPConcurrencyLimit      int
+	HTTPHostConcurrencyLimit  int
+	HTTPHostRequestsPerSecond float64
+	HTTPHostLimits            map[interface{}]interface{}
 
 	LogLevel int
 	LogSort  string
 
-	ExternalTimeout    int
-	RedirectLimit      int
-	StripQueryString   bool
-	StripQueryExcludes []interface{}
+	ExternalTimeout        int
+	ExternalRetries        int
+	ExternalRetryWait      string // Accepts golang time period strings, base of the exponential backoff between retries
+	ExternalRetryMaxWait   string // Accepts golang time period strings, cap on backoff and Retry-After waits
+	RedirectLimit          int
+	PermanentRedirectLevel int // Level to report links which permanently redirect at, alias for their rule
+	StripQueryString       bool
+	StripQueryExcludes     []interface{}
+	AcceptStatusCodes      map[interface{}]interface{}
 
 	EnableCache     bool
 	EnableLog       bool
+	EnableJSON      bool
+	EnableJUnit     bool
+	EnableSARIF     bool
 	OutputDir       string
 	OutputCacheFile string
 	OutputLogFile   string
+	OutputJSONFile  string
+	OutputJUnitFile string
+	OutputSARIFFile string
 	CacheExpires    string // Accepts golang time period strings, hours (16h) is really only useful option
 
+	CacheExpiresClientError  string // As CacheExpires, for 4xx and other unaccepted status codes
+	CacheExpiresServerError  string // As CacheExpires, for 5xx status codes
+	CacheExpiresNetworkError string // As CacheExpires, for timeouts, DNS and connection failures
+
+	BaselineFile  string // Path to a baseline of known errors, which don't fail the run
+	WriteBaseline bool   // Write the errors found to BaselineFile rather than reading it
+
+	GraphFile string // Path to write the link graph to, as Graphviz DOT if it ends .dot or .gv, otherwise JSON
+
 	// --- Internals below here ---
 	NoRun     bool   // When true does not run tests, used to inspect state in unit tests
 	VCREnable bool   // When true patches the govcr httpClient to mock network calls
@@ -86,6 +131,7 @@ func DefaultOptions() map[string]interface{} {
 	return map[string]interface{}{
 		"DirectoryIndex": "index.html",
 		"FileExtension":  ".html",
+		"BaseURL":        []interface{}{},
 
 		"CheckDoctype": true,
 		"CheckAnchors": true,
@@ -94,18 +140,31 @@ func DefaultOptions() map[string]interface{} {
 		"CheckScripts": true,
 		"CheckMeta":    true,
 		"CheckGeneric": true,
+		"CheckCSS":     false,
 
 		"CheckExternal":     true,
 		"CheckInternal":     true,
 		"CheckInternalHash": true,
+		"CheckExternalHash": false,
 		"CheckMailto":       true,
 		"CheckTel":          true,
 		"CheckFavicon":      false,
 		"CheckMetaRefresh":  true,
+		"CheckOpenGraph":    false,
+		"CheckHead":         false,
+		"CheckDuplicates":   false,
+		"CheckHreflang":     false,
+		"CheckOrphans":      false,
 
 		"EnforceHTML5": false,
 		"EnforceHTTPS": false,
 
+		"HeadDescriptionMinLength": 50,
+		"HeadDescriptionMaxLength": 160,
+
+		"EntryPoints":   []interface{}{},
+		"MaxClickDepth": 0,
+
 		"IgnoreURLs":         []interface{}{},
 		"IgnoreInternalURLs": []interface{}{},
 		"IgnoreHTTPS":        []interface{}{},
@@ -121,40 +180,68 @@ func DefaultOptions() map[string]interface{} {
 		"IgnoreSSLVerify":                     false,
 		"IgnoreTagAttribute":                  "data-proofer-ignore",
 
+		"Rules": map[interface{}]interface{}{},
+
 		"HTTPHeaders": map[interface{}]interface{}{
 			"Range":  "bytes=0-0", // If server supports prevents body being sent
 			"Accept": "*/*",       // We accept all content types
 		},
+		"HTTPMethod":     "GET",
+		"HTTPMethodURLs": map[interface{}]interface{}{},
 
-		"TestFilesConcurrently":    false,
-		"DocumentConcurrencyLimit": 128,
-		"HTTPConcurrencyLimit":     16,
+		"TestFilesConcurrently":     false,
+		"TestFilesPipeline":         false,
+		"DocumentConcurrencyLimit":  128,
+		"HTTPConcurrencyLimit":      16,
+		"HTTPHostConcurrencyLimit":  0,
+		"HTTPHostRequestsPerSecond": 0.0, // unlimited
+		"HTTPHostLimits":            map[interface{}]interface{}{},
 
 		"LogLevel": issues.LevelWarning,
 		"LogSort":  "document",
 
-		"ExternalTimeout":    15,
-		"RedirectLimit":      -1, // resort to built-in default
-		"StripQueryString":   true,
-		"StripQueryExcludes": []interface{}{"fonts.googleapis.com"},
+		"ExternalTimeout":        15,
+		"ExternalRetries":        0,
+		"ExternalRetryWait":      "1s",
+		"ExternalRetryMaxWait":   "30s",
+		"RedirectLimit":          -1, // resort to built-in default
+		"PermanentRedirectLevel": issues.LevelWarning,
+		"StripQueryString":       true,
+		"StripQueryExcludes":     []interface{}{"fonts.googleapis.com"},
+		"AcceptStatusCodes":      map[interface{}]interface{}{},
 
 		"EnableCache":     true,
 		"EnableLog":       true,
+		"EnableJSON":      false,
+		"EnableJUnit":     false,
+		"EnableSARIF":     false,
 		"OutputDir":       path.Join("tmp", ".htmltest"),
 		"OutputCacheFile": "refcache.json",
 		"OutputLogFile":   "htmltest.log",
+		"OutputJSONFile":  "htmltest.json",
+		"OutputJUnitFile": "htmltest.xml",
+		"OutputSARIFFile": "htmltest.sarif",
 		"CacheExpires":    "336h",
 
+		"CacheExpiresClientError":  "0s", // check again every run
+		"CacheExpiresServerError":  "0s",
+		"CacheExpiresNetworkError": "0s",
+
+		"BaselineFile":  "",
+		"WriteBaseline": false,
+
+		"GraphFile": "",
+
 		"NoRun":     false,
 		"VCREnable": false,
 		"Version":   "dev",
 	}
 }
 
-func (hT *HTMLTest) setOptions(optsUser map[string]interface{}) {
+func (hT *HTMLTest) setOptions(optsUser map[string]interface{}) error {
 	// Merge user and default options, set Opts var
 	optsMap := DefaultOptions()
-	mergo.Merge(&optsMap, optsUser, mergo.WithOverride)
+	mergo.Merge(&optsMap, coerceOptionTypes(optsUser), mergo.WithOverride)
 	hT.opts = Options{}
 	mergo.Map(&hT.opts, optsMap, mergo.WithOverride)
 
@@ -169,6 +256,58 @@ func (hT *HTMLTest) setOptions(optsUser map[string]interface{}) {
 				typeOfT.Field(i).Name, f.Type(), f.Interface())
 		}
 	}
+
+	// Parse durations up front so a typo isn't taken as no wait
+	var err error
+	if hT.retryWait, err = time.ParseDuration(hT.opts.ExternalRetryWait); err != nil {
+		return fmt.Errorf("ExternalRetryWait: %v", err)
+	}
+	if hT.retryMaxWait, err = time.ParseDuration(hT.opts.ExternalRetryMaxWait); err != nil {
+		return fmt.Errorf("ExternalRetryMaxWait: %v", err)
+	}
+	if hT.acceptRules, err = hT.opts.acceptRules(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// YAML gives us ints for whole numbers, which mergo won't map onto float
+// fields. Convert numeric user options to the kind of their Options field, and
+// a single string given for a list option to a list of one.
+func coerceOptionTypes(optsUser map[string]interface{}) map[string]interface{} {
+	optsType := reflect.TypeOf(Options{})
+	coerced := make(map[string]interface{}, len(optsUser))
+	for key, value := range optsUser {
+		coerced[key] = value
+		field, ok := optsType.FieldByName(key)
+		if !ok {
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Float64:
+			if f, ok := toFloat(value); ok {
+				coerced[key] = f
+			}
+		case reflect.Slice:
+			if s, ok := value.(string); ok {
+				coerced[key] = []interface{}{s}
+			}
+		}
+	}
+	return coerced
+}
+
+// Convert a numeric interface{} value, as found in config, to a float64.
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
 }
 
 // InList tests if key is in a slice/list.
@@ -211,3 +350,182 @@ func (opts *Options) isInternalURLIgnored(url string) bool {
 	}
 	return false
 }
+
+// Keys of a config dictionary as strings, sorted so regexes that overlap are
+// always tried in the same order.
+func sortedKeys(m map[interface{}]interface{}) ([]string, map[string]interface{}) {
+	keys := make([]string, 0, len(m))
+	values := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		keyStr := fmt.Sprintf("%v", key)
+		keys = append(keys, keyStr)
+		values[keyStr] = value
+	}
+	sort.Strings(keys)
+	return keys, values
+}
+
+// Which HTTP method should external checks of the given URL use? Uses the
+// method of a matching HTTPMethodURLs regex, otherwise HTTPMethod.
+func (opts *Options) httpMethod(url string) string {
+	patterns, methods := sortedKeys(opts.HTTPMethodURLs)
+	for _, pattern := range patterns {
+		if ok, _ := regexp.MatchString(pattern, url); ok {
+			return strings.ToUpper(fmt.Sprintf("%v", methods[pattern]))
+		}
+	}
+	return strings.ToUpper(opts.HTTPMethod)
+}
+
+// acceptRule : An AcceptStatusCodes entry, with its regex compiled.
+type acceptRule struct {
+	pattern *regexp.Regexp
+	codes   [][2]int // Accepted status codes, as inclusive ranges
+	level   int
+}
+
+// Compile AcceptStatusCodes, in sorted order. Each entry is either a list of
+// codes, or a dictionary of Codes and an optional Level. Malformed entries are
+// an error.
+func (opts *Options) acceptRules() ([]acceptRule, error) {
+	patterns, entries := sortedKeys(opts.AcceptStatusCodes)
+	rules := make([]acceptRule, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("AcceptStatusCodes %v is not a valid regex: %v", pattern, err)
+		}
+		rule := acceptRule{pattern: re, level: issues.LevelDebug}
+		codes := entries[pattern]
+		if settings, ok := codes.(map[interface{}]interface{}); ok {
+			codes = nil
+			for key, value := range settings {
+				switch key {
+				case "Codes":
+					codes = value
+				case "Level":
+					if rule.level, ok = toLevel(value); !ok {
+						return nil, fmt.Errorf("AcceptStatusCodes %v Level %v is not a level", pattern, value)
+					}
+				default:
+					return nil, fmt.Errorf("AcceptStatusCodes %v has unknown setting %v", pattern, key)
+				}
+			}
+		}
+		if rule.codes, err = statusCodes(codes); err != nil {
+			return nil, fmt.Errorf("AcceptStatusCodes %v %v", pattern, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Is the status code of the given external URL accepted by AcceptStatusCodes?
+// Returns the level to report it at, LevelDebug unless the entry sets Level.
+// The first matching regex, in sorted order, listing the code wins.
+func (hT *HTMLTest) acceptedStatus(url string, code int) (int, bool) {
+	for _, rule := range hT.acceptRules {
+		if !rule.pattern.MatchString(url) {
+			continue
+		}
+		for _, bounds := range rule.codes {
+			if bounds[0] <= code && code <= bounds[1] {
+				return rule.level, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Parse codes, a list, or single item, of status codes and ranges such as
+// "500-599", to inclusive ranges.
+func statusCodes(codes interface{}) ([][2]int, error) {
+	if codes == nil {
+		return nil, fmt.Errorf("has no Codes")
+	}
+	list, ok := codes.([]interface{})
+	if !ok {
+		list = []interface{}{codes}
+	}
+	ranges := make([][2]int, 0, len(list))
+	for _, item := range list {
+		if v, ok := toFloat(item); ok {
+			ranges = append(ranges, [2]int{int(v), int(v)})
+			continue
+		}
+		bounds := strings.SplitN(fmt.Sprintf("%v", item), "-", 2)
+		low, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, fmt.Errorf("code %v is not a status code or range", item)
+		}
+		high := low
+		if len(bounds) == 2 {
+			if high, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+				return nil, fmt.Errorf("code %v is not a status code or range", item)
+			}
+		}
+		ranges = append(ranges, [2]int{low, high})
+	}
+	return ranges, nil
+}
+
+// Convert a level from config, a name such as "warning" or a number, to one of
+// the issues level consts.
+func toLevel(value interface{}) (int, bool) {
+	if v, ok := toFloat(value); ok {
+		return int(v), true
+	}
+	if name, ok := value.(string); ok {
+		return issues.ParseLevel(name)
+	}
+	return 0, false
+}
+
+// Level each rule's issues are reported at, where Rules or the Ignore*
+// options, which are aliases for rules, change it from the level the check
+// gives. LevelNone turns a rule off. Entries in Rules win over aliases, those
+// with an unknown level are skipped.
+func (opts *Options) ruleLevels() map[string]int {
+	levels := make(map[string]int)
+	alias := func(set bool, level int, rules ...string) {
+		if !set {
+			return
+		}
+		for _, rule := range rules {
+			levels[rule] = level
+		}
+	}
+	alias(opts.IgnoreExternalBrokenLinks, issues.LevelWarning, issues.RuleExternalStatus,
+		issues.RuleExternalCanonical, issues.RuleExternalTimeout, issues.RuleExternalError,
+		issues.RuleExternalHashMissing, issues.RuleRefNotHTTPS)
+	alias(opts.IgnoreCanonicalBrokenLinks, issues.LevelWarning, issues.RuleExternalCanonical)
+	alias(opts.IgnoreInternalEmptyHash, issues.LevelNone, issues.RuleLinkHashEmpty)
+	alias(opts.IgnoreEmptyHref, issues.LevelNone, issues.RuleLinkHrefBlank)
+	alias(opts.IgnoreAltEmpty, issues.LevelNone, issues.RuleImgAltEmpty)
+	alias(opts.IgnoreAltMissing, issues.LevelNone, issues.RuleImgAltMissing,
+		issues.RuleImgAltEmpty, issues.RuleImgAltWhitespace)
+	alias(opts.IgnoreDirectoryMissingTrailingSlash, issues.LevelNone, issues.RuleLinkDirectoryNoSlash)
+	alias(true, opts.PermanentRedirectLevel, issues.RuleExternalRedirect)
+
+	rules, values := sortedKeys(opts.Rules)
+	for _, rule := range rules {
+		if ruleOff(values[rule]) {
+			levels[rule] = issues.LevelNone
+		} else if level, ok := toLevel(values[rule]); ok {
+			levels[rule] = level
+		}
+	}
+	return levels
+}
+
+// Does a level from Rules turn the rule off? YAML reads an unquoted off as
+// false.
+func ruleOff(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.EqualFold(v, "off")
+	case bool:
+		return !v
+	}
+	return false
+}
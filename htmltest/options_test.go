@@ -1,9 +1,11 @@
 package htmltest
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/daviddengcn/go-assert"
+	"github.com/wjdp/htmltest/issues"
 	"github.com/wjdp/htmltest/output"
 )
 
@@ -90,3 +92,88 @@ func TestMergeHTTPHeaders(t *testing.T) {
 
 	assert.Equals(t, "url ignored", hT.opts.HTTPHeaders["Range"], "bytes=0-10")
 }
+
+func TestAcceptedStatus(t *testing.T) {
+	userOpts := map[string]interface{}{
+		"AcceptStatusCodes": map[interface{}]interface{}{
+			"linkedin\\.com": map[interface{}]interface{}{
+				"Codes": 999,
+				"Level": "info",
+			},
+			"partners\\.": map[interface{}]interface{}{
+				"Codes": []interface{}{"401", " 403 ", "500-502"},
+				"Level": 2,
+			},
+			"twitter\\.com": []interface{}{400, "429"},
+		},
+		"NoRun": true,
+	}
+
+	hT, err := Test(userOpts)
+	output.CheckErrorPanic(err)
+
+	level, ok := hT.acceptedStatus("https://www.linkedin.com/in/x", 999)
+	assert.IsTrue(t, "999 accepted", ok)
+	assert.Equals(t, "999 level", level, issues.LevelInfo)
+	level, ok = hT.acceptedStatus("https://partners.example.com/", 501)
+	assert.IsTrue(t, "501 accepted", ok)
+	assert.Equals(t, "501 level", level, issues.LevelWarning)
+	_, ok = hT.acceptedStatus("https://partners.example.com/", 403)
+	assert.IsTrue(t, "403 accepted", ok)
+	_, ok = hT.acceptedStatus("https://partners.example.com/", 404)
+	assert.IsFalse(t, "404 not accepted", ok)
+	_, ok = hT.acceptedStatus("https://example.com/", 999)
+	assert.IsFalse(t, "999 elsewhere not accepted", ok)
+	level, ok = hT.acceptedStatus("https://twitter.com/x", 429)
+	assert.IsTrue(t, "list form accepted", ok)
+	assert.Equals(t, "list form level", level, issues.LevelDebug)
+}
+
+func TestAcceptStatusCodesMalformed(t *testing.T) {
+	// fails for bad regexes and entries which aren't codes or settings
+	for _, entries := range []map[interface{}]interface{}{
+		{"linkedin\\.com(": 999},
+		{"linkedin\\.com": "nine-nine-nine"},
+		{"linkedin\\.com": map[interface{}]interface{}{"Code": 999}},
+		{"linkedin\\.com": map[interface{}]interface{}{"Level": "warning"}},
+		{"linkedin\\.com": map[interface{}]interface{}{"Codes": 999, "Level": "loud"}},
+	} {
+		_, err := Test(map[string]interface{}{
+			"AcceptStatusCodes": entries,
+			"NoRun":             true,
+		})
+		assert.IsTrue(t, fmt.Sprintf("%v is an error", entries), err != nil)
+	}
+}
+
+func TestRuleLevels(t *testing.T) {
+	userOpts := map[string]interface{}{
+		"IgnoreAltMissing":          true,
+		"IgnoreExternalBrokenLinks": true,
+		"Rules": map[interface{}]interface{}{
+			"img/alt-empty":      "warning",
+			"external/timeout":   "Error",
+			"link/hash-missing":  "off",
+			"link/href-blank":    1,
+			"link/hash-empty":    false,
+			"meta/refresh-blank": "loud",
+		},
+		"NoRun": true,
+	}
+
+	hT, err := Test(userOpts)
+	output.CheckErrorPanic(err)
+
+	levels := hT.opts.ruleLevels()
+	assert.Equals(t, "alt-missing alias", levels[issues.RuleImgAltMissing], issues.LevelNone)
+	assert.Equals(t, "alt-empty rule over alias", levels[issues.RuleImgAltEmpty], issues.LevelWarning)
+	assert.Equals(t, "external/status alias", levels[issues.RuleExternalStatus], issues.LevelWarning)
+	assert.Equals(t, "timeout rule over alias", levels[issues.RuleExternalTimeout], issues.LevelError)
+	assert.Equals(t, "canonical default alias", levels[issues.RuleExternalCanonical], issues.LevelWarning)
+	assert.Equals(t, "permanent redirect default alias", levels[issues.RuleExternalRedirect], issues.LevelWarning)
+	assert.Equals(t, "hash-missing off", levels[issues.RuleLinkHashMissing], issues.LevelNone)
+	assert.Equals(t, "hash-empty off from YAML", levels[issues.RuleLinkHashEmpty], issues.LevelNone)
+	assert.Equals(t, "href-blank by number", levels[issues.RuleLinkHrefBlank], issues.LevelInfo)
+	_, ok := levels[issues.RuleMetaRefreshBlank]
+	assert.IsFalse(t, "unknown level skipped", ok)
+}
@@ -0,0 +1,104 @@
+package htmltest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/issues"
+)
+
+// pipeline : External references collected while documents are parsed and
+// checked, to be requested together once every document has been seen.
+type pipeline struct {
+	mutex   *sync.Mutex
+	pending []pendingExternal
+}
+
+// pendingExternal : An external reference waiting on its request.
+type pendingExternal struct {
+	ref       *htmldoc.Reference
+	urlStr    string
+	checkHash bool
+}
+
+func newPipeline() *pipeline {
+	return &pipeline{mutex: &sync.Mutex{}}
+}
+
+// add : Queue an external reference, thread safe.
+func (p *pipeline) add(ref *htmldoc.Reference, urlStr string, checkHash bool) {
+	p.mutex.Lock()
+	p.pending = append(p.pending, pendingExternal{ref, urlStr, checkHash})
+	p.mutex.Unlock()
+}
+
+// testDocumentsPipeline : Test documents in three phases. First every document
+// is parsed and checked, with external references collected rather than
+// requested. Then the unique external URLs are requested by a pool of
+// workers. Finally the results are attached back to each reference, in
+// document order, and issues raised.
+func (hT *HTMLTest) testDocumentsPipeline() {
+	hT.pipeline = newPipeline()
+	hT.forEachDocument(hT.testDocument)
+	pending := hT.pipeline.pending
+	hT.pipeline = nil
+
+	// Documents tested concurrently queue in any order, put them back in
+	// store order. Each document's own references are already in order.
+	docIndex := make(map[*htmldoc.Document]int, len(hT.documentStore.Documents))
+	for i, document := range hT.documentStore.Documents {
+		docIndex[document] = i
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return docIndex[pending[i].ref.Document] < docIndex[pending[j].ref.Document]
+	})
+
+	// Reduce to the unique requests, in the order first seen
+	keys := make([]requestKey, 0)
+	firsts := make(map[requestKey]pendingExternal)
+	for _, p := range pending {
+		key := requestKey{htmldoc.URLStripFragment(p.urlStr), p.checkHash}
+		if _, ok := firsts[key]; !ok {
+			keys = append(keys, key)
+			firsts[key] = p
+		}
+	}
+
+	hT.issueStore.AddIssue(issues.Issue{
+		Level: issues.LevelInfo,
+		Message: fmt.Sprintf("%d unique external URLs across %d references",
+			len(keys), len(pending)),
+	})
+
+	// Request each URL with a pool of workers
+	results := make(map[requestKey]*externalResult, len(keys))
+	var resultsMutex sync.Mutex
+	jobs := make(chan requestKey)
+	var wg sync.WaitGroup
+	for i := 0; i < hT.opts.HTTPConcurrencyLimit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				p := firsts[key]
+				result := hT.fetchExternal(p.ref, p.urlStr, p.checkHash)
+				resultsMutex.Lock()
+				results[key] = result
+				resultsMutex.Unlock()
+			}
+		}()
+	}
+	for _, key := range keys {
+		jobs <- key
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Attach results back to references
+	for _, p := range pending {
+		key := requestKey{htmldoc.URLStripFragment(p.urlStr), p.checkHash}
+		hT.reportExternal(p.ref, p.urlStr, p.checkHash, results[key])
+	}
+}
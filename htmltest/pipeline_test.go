@@ -0,0 +1,78 @@
+package htmltest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+	"github.com/wjdp/htmltest/issues"
+)
+
+func TestPipelineSharedExternal(t *testing.T) {
+	// requests each unique external URL once and reports every reference
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	hT := tTestDirectoryServer(t, "fixtures/concurrency/sharedExternal", server,
+		map[string]interface{}{"TestFilesPipeline": true})
+	tExpectIssueCount(t, hT, 6)
+	tExpectIssue(t, hT, "Non-OK status: 404", 6)
+	tExpectIssue(t, hT, "1 unique external URLs across 6 references", 1)
+	assert.Equals(t, "server hits", atomic.LoadInt32(&hits), int32(1))
+}
+
+func TestPipelineOrdering(t *testing.T) {
+	// issues are raised in document order
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	hT := tTestDirectoryServer(t, "fixtures/concurrency/sharedExternal", server,
+		map[string]interface{}{"TestFilesPipeline": true})
+	docs := make([]string, 0)
+	for _, issue := range hT.issueStore.Issues(issues.LevelError) {
+		docs = append(docs, issue.Reference.Document.SitePath)
+	}
+	expected := make([]string, 0)
+	for _, document := range hT.documentStore.Documents {
+		expected = append(expected, document.SitePath)
+	}
+	assert.StringEquals(t, "issue order", docs, expected)
+}
+
+func TestPipelineConcurrently(t *testing.T) {
+	// documents are tested by the bounded pool, issues still in document order
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	hT := tTestDirectoryServer(t, "fixtures/concurrency/sharedExternal", server,
+		map[string]interface{}{"TestFilesPipeline": true,
+			"TestFilesConcurrently": true, "DocumentConcurrencyLimit": 2})
+	tExpectIssue(t, hT, "running in concurrent mode, this is experimental", 1)
+	tExpectIssue(t, hT, "Non-OK status: 404", 6)
+	assert.Equals(t, "server hits", atomic.LoadInt32(&hits), int32(1))
+	docs := make([]string, 0)
+	for _, issue := range hT.issueStore.Issues(issues.LevelError) {
+		docs = append(docs, issue.Reference.Document.SitePath)
+	}
+	expected := make([]string, 0)
+	for _, document := range hT.documentStore.Documents {
+		expected = append(expected, document.SitePath)
+	}
+	assert.StringEquals(t, "issue order", docs, expected)
+}
+
+func TestPipelineInternal(t *testing.T) {
+	// internal checks are unaffected
+	hT := tTestDirectoryOpts("fixtures/documents/folder-not-ok",
+		map[string]interface{}{"TestFilesPipeline": true})
+	assert.Equals(t, "CountErrors", hT.CountErrors(), 2)
+}
@@ -0,0 +1,86 @@
+package htmltest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/issues"
+	"github.com/wjdp/htmltest/refcache"
+)
+
+// redirectChain : The redirects the http client followed to get resp, in the
+// order they were followed. Empty if resp came straight from the first
+// request.
+func redirectChain(resp *http.Response) []refcache.Redirect {
+	var chain []refcache.Redirect
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		chain = append([]refcache.Redirect{{
+			StatusCode: req.Response.StatusCode,
+			URL:        req.URL.String(),
+		}}, chain...)
+	}
+	return chain
+}
+
+// Is code one of the redirects which tell us to update our link?
+func permanentRedirect(code int) bool {
+	return code == http.StatusMovedPermanently || code == http.StatusPermanentRedirect
+}
+
+// Text describing a redirect chain, e.g. "301 https://a/ -> 302 https://b/"
+func redirectChainText(redirects []refcache.Redirect) string {
+	hops := make([]string, len(redirects))
+	for i, redirect := range redirects {
+		hops[i] = fmt.Sprintf("%d %s", redirect.StatusCode, redirect.URL)
+	}
+	return strings.Join(hops, " -> ")
+}
+
+// reportRedirects : Add issues for the redirects followed when checking ref.
+// Permanent redirects are warnings, naming the final location so the link can
+// be updated, PermanentRedirectLevel is an alias for their rule. Redirects from https to http are
+// warnings, or errors with EnforceHTTPS.
+func (hT *HTMLTest) reportRedirects(ref *htmldoc.Reference, urlStr string, redirects []refcache.Redirect) {
+	if len(redirects) == 0 {
+		return
+	}
+
+	hT.issueStore.AddIssue(issues.Issue{
+		Level:     issues.LevelDebug,
+		Message:   "redirects: " + redirectChainText(redirects),
+		Reference: ref,
+	})
+
+	finalURL := redirects[len(redirects)-1].URL
+	for _, redirect := range redirects {
+		if permanentRedirect(redirect.StatusCode) {
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:     issues.LevelWarning,
+				Message:   "permanently redirects to " + finalURL,
+				Rule:      issues.RuleExternalRedirect,
+				Reference: ref,
+			})
+			break
+		}
+	}
+
+	from := urlStr
+	for _, redirect := range redirects {
+		if strings.HasPrefix(from, "https:") && strings.HasPrefix(redirect.URL, "http:") {
+			issueLevel := issues.LevelWarning
+			if hT.opts.EnforceHTTPS && !hT.opts.isInsecureURLIgnored(redirect.URL) {
+				issueLevel = issues.LevelError
+			}
+			hT.issueStore.AddIssue(issues.Issue{
+				Level:     issueLevel,
+				Message:   "redirect downgrades to http: " + redirect.URL,
+				Rule:      issues.RuleExternalDowngrade,
+				Reference: ref,
+			})
+			break
+		}
+		from = redirect.URL
+	}
+}
@@ -0,0 +1,100 @@
+package htmltest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+	"github.com/wjdp/htmltest/issues"
+	"github.com/wjdp/htmltest/refcache"
+)
+
+// Serves /new, with the other paths redirecting to it
+func tRedirectServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/permanent":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/temporary":
+			http.Redirect(w, r, "/new", http.StatusFound)
+		case "/chain":
+			http.Redirect(w, r, "/moved", http.StatusTemporaryRedirect)
+		case "/moved":
+			http.Redirect(w, r, "/new", http.StatusPermanentRedirect)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+}
+
+func TestRedirectPermanent(t *testing.T) {
+	// warns about links through a permanent redirect, naming the final URL
+	server := tRedirectServer()
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/redirects.html", server, map[string]interface{}{})
+	tExpectIssueCount(t, hT, 0)
+	tExpectIssue(t, hT, "permanently redirects to "+server.URL+"/new", 2)
+	assert.Equals(t, "warnings", hT.issueStore.Count(issues.LevelWarning), 2)
+	tExpectIssue(t, hT, "redirects: 307 "+server.URL+"/moved -> 308 "+server.URL+"/new", 1)
+}
+
+func TestRedirectPermanentLevel(t *testing.T) {
+	// reports permanent redirects at PermanentRedirectLevel
+	server := tRedirectServer()
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/redirects.html", server,
+		map[string]interface{}{"PermanentRedirectLevel": issues.LevelError})
+	tExpectIssueCount(t, hT, 2)
+	tExpectIssue(t, hT, "permanently redirects to", 2)
+}
+
+func TestRedirectPermanentDisabled(t *testing.T) {
+	// doesn't report permanent redirects with PermanentRedirectLevel set to none
+	server := tRedirectServer()
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/redirects.html", server,
+		map[string]interface{}{"PermanentRedirectLevel": issues.LevelNone})
+	tExpectIssueCount(t, hT, 0)
+	tExpectIssue(t, hT, "permanently redirects to", 0)
+	assert.Equals(t, "warnings", hT.issueStore.Count(issues.LevelWarning), 0)
+}
+
+func TestRedirectDowngrade(t *testing.T) {
+	// warns about https links which redirect to http, fails with EnforceHTTPS
+	plainServer := tRedirectServer()
+	defer plainServer.Close()
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, plainServer.URL+"/new", http.StatusFound)
+	}))
+	defer tlsServer.Close()
+
+	hT := tTestFileServer(t, "fixtures/links/serverLink.html", tlsServer,
+		map[string]interface{}{"IgnoreSSLVerify": true})
+	tExpectIssueCount(t, hT, 0)
+	tExpectIssue(t, hT, "redirect downgrades to http: "+plainServer.URL+"/new", 1)
+	assert.Equals(t, "warnings", hT.issueStore.Count(issues.LevelWarning), 1)
+
+	hT = tTestFileServer(t, "fixtures/links/serverLink.html", tlsServer,
+		map[string]interface{}{"IgnoreSSLVerify": true, "EnforceHTTPS": true})
+	tExpectIssueCount(t, hT, 1)
+	tExpectIssue(t, hT, "redirect downgrades to http", 1)
+}
+
+func TestRedirectChain(t *testing.T) {
+	// records each hop of the redirects followed
+	server := tRedirectServer()
+	defer server.Close()
+	resp, err := http.Get(server.URL + "/chain")
+	assert.NoErrorf(t, "get", err)
+	resp.Body.Close()
+	assert.StringEquals(t, "chain", redirectChain(resp), []refcache.Redirect{
+		{StatusCode: http.StatusTemporaryRedirect, URL: server.URL + "/moved"},
+		{StatusCode: http.StatusPermanentRedirect, URL: server.URL + "/new"},
+	})
+
+	resp, err = http.Get(server.URL + "/new")
+	assert.NoErrorf(t, "get", err)
+	resp.Body.Close()
+	assert.Equals(t, "no redirects", len(redirectChain(resp)), 0)
+}
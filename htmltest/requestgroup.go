@@ -0,0 +1,66 @@
+package htmltest
+
+import (
+	"fmt"
+	"sync"
+)
+
+// requestKey : Identifies an external request, requests that need the page
+// body are distinct from those that don't.
+type requestKey struct {
+	url  string
+	body bool
+}
+
+// requestGroup : Coalesces concurrent requests for the same external URL. The
+// first caller makes the request, callers arriving while it's in flight wait
+// and share its result.
+type requestGroup struct {
+	mutex   *sync.Mutex
+	flights map[requestKey]*requestFlight
+}
+
+// requestFlight : A request in progress.
+type requestFlight struct {
+	wg     sync.WaitGroup
+	result *externalResult
+}
+
+func newRequestGroup() *requestGroup {
+	return &requestGroup{
+		mutex:   &sync.Mutex{},
+		flights: make(map[requestKey]*requestFlight),
+	}
+}
+
+// do : Call fn unless a call for key is already in flight, in which case
+// wait for that one. Returns the result and whether it was shared from
+// another caller's flight.
+func (g *requestGroup) do(key requestKey, fn func() *externalResult) (*externalResult, bool) {
+	g.mutex.Lock()
+	if f, ok := g.flights[key]; ok {
+		g.mutex.Unlock()
+		f.wg.Wait()
+		return f.result, true
+	}
+	f := &requestFlight{}
+	f.wg.Add(1)
+	g.flights[key] = f
+	g.mutex.Unlock()
+
+	// Release waiters and the flight even if fn panics, waiters get an error
+	// result and the panic carries on up this caller
+	defer func() {
+		if r := recover(); r != nil {
+			f.result = &externalResult{err: fmt.Errorf("request panicked: %v", r)}
+			defer panic(r)
+		}
+		f.wg.Done()
+		g.mutex.Lock()
+		delete(g.flights, key)
+		g.mutex.Unlock()
+	}()
+
+	f.result = fn()
+	return f.result, false
+}
@@ -0,0 +1,113 @@
+package htmltest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/daviddengcn/go-assert"
+)
+
+func TestRequestGroupCoalesces(t *testing.T) {
+	g := newRequestGroup()
+	var calls int32
+	var wg sync.WaitGroup
+	release := make(chan bool)
+	results := make([]*externalResult, 8)
+	shared := make([]bool, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], shared[i] = g.do(requestKey{"https://example.com/", false}, func() *externalResult {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				return &externalResult{statusCode: http.StatusOK}
+			})
+		}(i)
+	}
+	// Give every goroutine time to join the flight before it lands
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equals(t, "calls", atomic.LoadInt32(&calls), int32(1))
+	sharedCount := 0
+	for i := range results {
+		assert.Equals(t, "result", results[i], results[0])
+		if shared[i] {
+			sharedCount++
+		}
+	}
+	assert.Equals(t, "shared results", sharedCount, 7)
+}
+
+func TestRequestGroupKeys(t *testing.T) {
+	// requests for the body are not shared with those without
+	g := newRequestGroup()
+	r1, _ := g.do(requestKey{"https://example.com/", false}, func() *externalResult {
+		r2, shared := g.do(requestKey{"https://example.com/", true}, func() *externalResult {
+			return &externalResult{statusCode: http.StatusOK, hashes: []string{}}
+		})
+		assert.IsFalse(t, "distinct key not shared", shared)
+		assert.IsTrue(t, "body result", r2.hashes != nil)
+		return &externalResult{statusCode: http.StatusPartialContent}
+	})
+	assert.Equals(t, "status", r1.statusCode, http.StatusPartialContent)
+}
+
+func TestConcurrencyDirSharedExternal(t *testing.T) {
+	// references to the same URL across concurrently tested documents result
+	// in a single request
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	hT := tTestDirectoryServer(t, "fixtures/concurrency/sharedExternal", server,
+		map[string]interface{}{"TestFilesConcurrently": true})
+	// Every reference still gets its own issue
+	tExpectIssueCount(t, hT, 6)
+	tExpectIssue(t, hT, "Non-OK status: 404", 6)
+	assert.Equals(t, "server hits", atomic.LoadInt32(&hits), int32(1))
+}
+
+func TestRequestGroupPanic(t *testing.T) {
+	// a panicking request releases its waiters, with an error, and its flight
+	g := newRequestGroup()
+	key := requestKey{"https://example.com/", false}
+	started := make(chan bool)
+	waited := make(chan *externalResult)
+	repanicked := make(chan interface{}, 1)
+	go func() {
+		defer func() { repanicked <- recover() }()
+		g.do(key, func() *externalResult {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			panic("request failed")
+		})
+	}()
+	<-started
+	go func() {
+		result, _ := g.do(key, func() *externalResult { return nil })
+		waited <- result
+	}()
+	select {
+	case result := <-waited:
+		assert.IsTrue(t, "waiter result", result != nil && result.err != nil)
+	case <-time.After(time.Second):
+		t.Fatal("waiter blocked after panic")
+	}
+	assert.Equals(t, "caller panic", <-repanicked, "request failed")
+
+	result, shared := g.do(key, func() *externalResult {
+		return &externalResult{statusCode: http.StatusOK}
+	})
+	assert.IsFalse(t, "new flight", shared)
+	assert.Equals(t, "status", result.statusCode, http.StatusOK)
+}
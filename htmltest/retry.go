@@ -0,0 +1,117 @@
+package htmltest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// doRequest : Send req with hT.httpClient, retrying transient failures up to
+// ExternalRetries times. Waits between attempts back off exponentially from
+// ExternalRetryWait, with jitter, or follow the server's Retry-After header on
+// 429 and 503 responses. Both are capped at ExternalRetryMaxWait. Returns the
+// final response or error along with the number of attempts made.
+func (hT *HTMLTest) doRequest(req *http.Request) (*http.Response, int, error) {
+	attempt := 0
+	for {
+		attempt++
+
+		hT.httpScheduler.acquire(req.URL.Hostname())
+		resp, err := hT.httpClient.Do(req)
+		hT.httpScheduler.release(req.URL.Hostname())
+
+		if attempt > hT.opts.ExternalRetries || !retryable(resp, err) {
+			return resp, attempt, err
+		}
+
+		wait := backoff(hT.retryWait, hT.retryMaxWait, attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp, time.Now()); ok {
+				wait = after
+				if hT.retryMaxWait > 0 && wait > hT.retryMaxWait {
+					wait = hT.retryMaxWait
+				}
+			}
+			// We're discarding this response, let the connection be reused
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		time.Sleep(wait)
+	}
+}
+
+// Is the outcome of a request worth trying again?
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			return true
+		}
+		// Hosts which don't exist won't on the next attempt either
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false
+		}
+		return strings.Contains(err.Error(), "Client.Timeout") ||
+			strings.Contains(err.Error(), "dial tcp")
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusServiceUnavailable
+}
+
+// Exponential backoff from base, doubling per attempt up to max (if set).
+// "Equal jitter" is applied, the wait is a random duration in the upper half
+// of the backoff so simultaneous retries against a host spread out.
+func backoff(base, max time.Duration, attempt int) time.Duration {
+	wait := base
+	for i := 1; i < attempt && (max <= 0 || wait < max); i++ {
+		wait *= 2
+	}
+	if max > 0 && wait > max {
+		wait = max
+	}
+	if wait <= 1 {
+		return wait
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)))
+}
+
+// Read the Retry-After header of a 429 or 503 response, which may either be
+// a number of seconds or an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests &&
+		resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	header := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if date.Before(now) {
+			return 0, true
+		}
+		return date.Sub(now), true
+	}
+	return 0, false
+}
+
+// Suffix for issue messages when a request took more than one attempt.
+func attemptsText(attempts int) string {
+	if attempts > 1 {
+		return fmt.Sprintf(" (%d attempts)", attempts)
+	}
+	return ""
+}
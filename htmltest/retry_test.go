@@ -0,0 +1,130 @@
+package htmltest
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/daviddengcn/go-assert"
+)
+
+func TestRetryUntilOK(t *testing.T) {
+	// passes when a server recovers within ExternalRetries
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if hits < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/serverLink.html", server,
+		map[string]interface{}{"ExternalRetries": 2, "ExternalRetryWait": "1ms"})
+	tExpectIssueCount(t, hT, 0)
+	assert.Equals(t, "server hits", hits, 3)
+}
+
+func TestRetryExhausted(t *testing.T) {
+	// fails with the final status and attempt count when retries run out
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/serverLink.html", server,
+		map[string]interface{}{"ExternalRetries": 2, "ExternalRetryWait": "1ms"})
+	tExpectIssueCount(t, hT, 1)
+	tExpectIssue(t, hT, "Non-OK status: 429 (3 attempts)", 1)
+	assert.Equals(t, "server hits", hits, 3)
+}
+
+func TestRetryDisabledByDefault(t *testing.T) {
+	// fails straight away when ExternalRetries isn't set
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/serverLink.html", server, map[string]interface{}{})
+	tExpectIssueCount(t, hT, 1)
+	tExpectIssue(t, hT, "Non-OK status: 503", 1)
+	assert.Equals(t, "server hits", hits, 1)
+}
+
+func TestRetryNotFound(t *testing.T) {
+	// does not retry responses that won't change
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	hT := tTestFileServer(t, "fixtures/links/serverLink.html", server,
+		map[string]interface{}{"ExternalRetries": 2, "ExternalRetryWait": "1ms"})
+	tExpectIssueCount(t, hT, 1)
+	assert.Equals(t, "server hits", hits, 1)
+}
+
+func TestBackoff(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt, max := range map[int]time.Duration{1: 100, 2: 200, 3: 400, 4: 500, 10: 500} {
+		wait := backoff(base, 500*time.Millisecond, attempt)
+		assert.IsTrue(t, "backoff within upper bound", wait <= max*time.Millisecond)
+		assert.IsTrue(t, "backoff within lower bound", wait >= max*time.Millisecond/2)
+	}
+	assert.Equals(t, "zero backoff", backoff(0, 0, 3), time.Duration(0))
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+
+	_, ok := retryAfter(resp, now)
+	assert.IsFalse(t, "no header", ok)
+
+	resp.Header.Set("Retry-After", "120")
+	wait, ok := retryAfter(resp, now)
+	assert.IsTrue(t, "seconds header", ok)
+	assert.Equals(t, "seconds header", wait, 2*time.Minute)
+
+	resp.Header.Set("Retry-After", "Wed, 01 Jan 2020 12:00:30 GMT")
+	wait, ok = retryAfter(resp, now)
+	assert.IsTrue(t, "date header", ok)
+	assert.Equals(t, "date header", wait, 30*time.Second)
+
+	resp.Header.Set("Retry-After", "soon")
+	_, ok = retryAfter(resp, now)
+	assert.IsFalse(t, "invalid header", ok)
+
+	resp.StatusCode = http.StatusNotFound
+	resp.Header.Set("Retry-After", "120")
+	_, ok = retryAfter(resp, now)
+	assert.IsFalse(t, "not a 429 or 503", ok)
+}
+
+func TestRetryWaitMalformed(t *testing.T) {
+	// fails for waits which aren't durations
+	for _, opt := range []string{"ExternalRetryWait", "ExternalRetryMaxWait"} {
+		_, err := Test(map[string]interface{}{opt: "5 seconds", "NoRun": true})
+		assert.IsTrue(t, opt+" is an error", err != nil)
+	}
+}
+
+func TestRetryableDNSNotFound(t *testing.T) {
+	// doesn't retry hosts which don't exist
+	err := &url.Error{Op: "Get", URL: "http://nope.invalid/", Err: &net.OpError{
+		Op: "dial", Net: "tcp",
+		Err: &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true},
+	}}
+	assert.IsFalse(t, "not found retryable", retryable(nil, err))
+	err.Err.(*net.OpError).Err = &net.DNSError{Err: "server misbehaving", Name: "nope.invalid"}
+	assert.IsTrue(t, "other dns error retryable", retryable(nil, err))
+}
@@ -0,0 +1,137 @@
+package htmltest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// hostLimit : Limits applied to requests made to a single host. A zero value
+// for either field means that dimension isn't limited.
+type hostLimit struct {
+	concurrency       int     // Maximum number of requests open at once
+	requestsPerSecond float64 // Maximum rate requests are started at
+}
+
+// httpScheduler : Gatekeeper for external requests, replaces a bare channel.
+// Limits the number of requests open at once, globally and per host, and
+// spaces out requests to a host to keep within its request rate.
+type httpScheduler struct {
+	global       chan bool             // Global concurrency limiter
+	defaultLimit hostLimit             // Limits for hosts not in overrides
+	overrides    map[string]hostLimit  // Limits for specific hosts
+	hosts        map[string]*hostState // Per host state, created on first use
+	mutex        *sync.Mutex           // Controls access to hosts and their state
+}
+
+// hostState : Scheduling state of a single host.
+type hostState struct {
+	slots    chan bool     // Host concurrency limiter, nil if unlimited
+	interval time.Duration // Minimum gap between request starts, zero if unlimited
+	next     time.Time     // Earliest time the next request may start
+}
+
+func newHTTPScheduler(globalLimit int, defaultLimit hostLimit,
+	overrides map[string]hostLimit) *httpScheduler {
+	return &httpScheduler{
+		global:       make(chan bool, globalLimit),
+		defaultLimit: defaultLimit,
+		overrides:    overrides,
+		hosts:        make(map[string]*hostState),
+		mutex:        &sync.Mutex{},
+	}
+}
+
+// Get or create the state for host, thread safe.
+func (s *httpScheduler) host(host string) *hostState {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	hS, ok := s.hosts[host]
+	if !ok {
+		limit, ok := s.overrides[host]
+		if !ok {
+			limit = s.defaultLimit
+		}
+		hS = &hostState{}
+		if limit.concurrency > 0 {
+			hS.slots = make(chan bool, limit.concurrency)
+		}
+		if limit.requestsPerSecond > 0 {
+			hS.interval = time.Duration(float64(time.Second) / limit.requestsPerSecond)
+		}
+		s.hosts[host] = hS
+	}
+	return hS
+}
+
+// acquire : Block until a request to host may be made. Every call must be
+// paired with a call to release once the request is complete.
+func (s *httpScheduler) acquire(host string) {
+	hS := s.host(host)
+
+	// Take a host slot first, so requests queued against a busy host don't
+	// tie up global slots other hosts could be using.
+	if hS.slots != nil {
+		hS.slots <- true
+	}
+
+	if hS.interval > 0 {
+		// Reserve the next start time for this host and wait for it
+		s.mutex.Lock()
+		now := time.Now()
+		start := hS.next
+		if start.Before(now) {
+			start = now
+		}
+		hS.next = start.Add(hS.interval)
+		s.mutex.Unlock()
+		time.Sleep(start.Sub(now))
+	}
+
+	s.global <- true
+}
+
+// release : Mark a request to host as complete.
+func (s *httpScheduler) release(host string) {
+	<-s.global
+	if hS := s.host(host); hS.slots != nil {
+		<-hS.slots
+	}
+}
+
+// Build the per host limits from options, HTTPHostLimits maps host names to
+// maps with ConcurrencyLimit and/or RequestsPerSecond keys. Unset keys fall
+// back to the defaults. Malformed entries are an error.
+func (opts *Options) hostLimits() (hostLimit, map[string]hostLimit, error) {
+	defaultLimit := hostLimit{
+		concurrency:       opts.HTTPHostConcurrencyLimit,
+		requestsPerSecond: opts.HTTPHostRequestsPerSecond,
+	}
+	overrides := make(map[string]hostLimit)
+	for host, value := range opts.HTTPHostLimits {
+		settings, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return defaultLimit, nil, fmt.Errorf(
+				"HTTPHostLimits entry for %v should be a dictionary of settings", host)
+		}
+		limit := defaultLimit
+		for key, setting := range settings {
+			v, ok := toFloat(setting)
+			if !ok || v < 0 {
+				return defaultLimit, nil, fmt.Errorf(
+					"HTTPHostLimits %v %v should be zero or more, not %v", host, key, setting)
+			}
+			switch key {
+			case "ConcurrencyLimit":
+				limit.concurrency = int(v)
+			case "RequestsPerSecond":
+				limit.requestsPerSecond = v
+			default:
+				return defaultLimit, nil, fmt.Errorf("HTTPHostLimits %v has unknown setting %v", host, key)
+			}
+		}
+		overrides[fmt.Sprintf("%v", host)] = limit
+	}
+	return defaultLimit, overrides, nil
+}
@@ -0,0 +1,118 @@
+package htmltest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daviddengcn/go-assert"
+	"github.com/wjdp/htmltest/output"
+)
+
+// Run n acquire/release cycles on host concurrently, returning the peak number
+// of holders at once.
+func tSchedulerPeak(s *httpScheduler, host string, n int) int {
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	current, peak := 0, 0
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.acquire(host)
+			mutex.Lock()
+			current++
+			if current > peak {
+				peak = current
+			}
+			mutex.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mutex.Lock()
+			current--
+			mutex.Unlock()
+			s.release(host)
+		}()
+	}
+	wg.Wait()
+	return peak
+}
+
+func TestSchedulerHostConcurrency(t *testing.T) {
+	s := newHTTPScheduler(16, hostLimit{concurrency: 2},
+		map[string]hostLimit{"busy.example.com": {concurrency: 1}})
+	assert.Equals(t, "default host peak", tSchedulerPeak(s, "example.com", 10), 2)
+	assert.Equals(t, "override host peak", tSchedulerPeak(s, "busy.example.com", 10), 1)
+}
+
+func TestSchedulerGlobalConcurrency(t *testing.T) {
+	s := newHTTPScheduler(3, hostLimit{}, map[string]hostLimit{})
+	assert.Equals(t, "global peak", tSchedulerPeak(s, "example.com", 10), 3)
+}
+
+func TestSchedulerRate(t *testing.T) {
+	s := newHTTPScheduler(16, hostLimit{requestsPerSecond: 50},
+		map[string]hostLimit{})
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		s.acquire("example.com")
+		s.release("example.com")
+	}
+	// First request is immediate, the following three are 20ms apart
+	assert.IsTrue(t, "rate limited", time.Since(start) >= 60*time.Millisecond)
+
+	// Other hosts are unaffected
+	start = time.Now()
+	s.acquire("other.example.com")
+	s.release("other.example.com")
+	assert.IsTrue(t, "other host not limited", time.Since(start) < 20*time.Millisecond)
+}
+
+func TestHostLimitsOptions(t *testing.T) {
+	hT, err := Test(map[string]interface{}{
+		"HTTPHostConcurrencyLimit":  2,
+		"HTTPHostRequestsPerSecond": 5,
+		"HTTPHostLimits": map[interface{}]interface{}{
+			"docs.example.com": map[interface{}]interface{}{
+				"ConcurrencyLimit":  1,
+				"RequestsPerSecond": 0.5,
+			},
+			"api.example.com": map[interface{}]interface{}{
+				"ConcurrencyLimit": 8,
+			},
+		},
+		"NoRun": true,
+	})
+	output.CheckErrorPanic(err)
+
+	defaultLimit, overrides, err := hT.opts.hostLimits()
+	assert.NoErrorf(t, "host limits", err)
+	assert.Equals(t, "default limit", defaultLimit, hostLimit{2, 5})
+	assert.Equals(t, "override", overrides["docs.example.com"], hostLimit{1, 0.5})
+	assert.Equals(t, "partial override", overrides["api.example.com"], hostLimit{8, 5})
+}
+
+func TestHostLimitsDefault(t *testing.T) {
+	// hosts aren't limited beyond HTTPConcurrencyLimit by default
+	hT, err := Test(map[string]interface{}{"NoRun": true})
+	output.CheckErrorPanic(err)
+	defaultLimit, _, err := hT.opts.hostLimits()
+	assert.NoErrorf(t, "host limits", err)
+	assert.Equals(t, "default limit", defaultLimit, hostLimit{0, 0})
+}
+
+func TestHostLimitsMalformed(t *testing.T) {
+	// fails for entries which aren't maps of known settings to numbers
+	for _, entry := range []interface{}{
+		4,
+		map[interface{}]interface{}{"Concurrency": 1},
+		map[interface{}]interface{}{"ConcurrencyLimit": "lots"},
+		map[interface{}]interface{}{"RequestsPerSecond": -1},
+	} {
+		_, err := Test(map[string]interface{}{
+			"HTTPHostLimits": map[interface{}]interface{}{"docs.example.com": entry},
+			"NoRun":          true,
+		})
+		assert.IsTrue(t, fmt.Sprintf("%v is an error", entry), err != nil)
+	}
+}
@@ -1,7 +1,10 @@
 package htmltest
 
 import (
+	"io/ioutil"
+	"net/http/httptest"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/imdario/mergo"
@@ -91,6 +94,45 @@ func tTestDirectoryOpts(filename string, tOpts map[string]interface{}) *HTMLTest
 	return hT
 }
 
+// Test a single file which links to a local test server, the placeholder
+// SERVER_URL in the file is swapped for the server's URL. The file is copied
+// into a temporary directory to do this.
+func tTestFileServer(t *testing.T, filename string, server *httptest.Server,
+	tOpts map[string]interface{}) *HTMLTest {
+	b, err := ioutil.ReadFile(filename)
+	output.CheckErrorPanic(err)
+	dir := t.TempDir()
+	err = ioutil.WriteFile(path.Join(dir, path.Base(filename)),
+		[]byte(strings.ReplaceAll(string(b), "SERVER_URL", server.URL)), 0644)
+	output.CheckErrorPanic(err)
+	opts := defaultFileTestOpts(path.Join(dir, path.Base(filename)))
+	mergo.MergeWithOverwrite(&opts, tOpts)
+	hT, err := Test(opts)
+	output.CheckErrorPanic(err)
+	return hT
+}
+
+// Test a directory of files which link to a local test server, as with
+// tTestFileServer the placeholder SERVER_URL is swapped for the server's URL.
+func tTestDirectoryServer(t *testing.T, dirname string, server *httptest.Server,
+	tOpts map[string]interface{}) *HTMLTest {
+	fis, err := ioutil.ReadDir(dirname)
+	output.CheckErrorPanic(err)
+	dir := t.TempDir()
+	for _, fi := range fis {
+		b, err := ioutil.ReadFile(path.Join(dirname, fi.Name()))
+		output.CheckErrorPanic(err)
+		err = ioutil.WriteFile(path.Join(dir, fi.Name()),
+			[]byte(strings.ReplaceAll(string(b), "SERVER_URL", server.URL)), 0644)
+		output.CheckErrorPanic(err)
+	}
+	opts := defaultDirectoryTestOpts(dir)
+	mergo.MergeWithOverwrite(&opts, tOpts)
+	hT, err := Test(opts)
+	output.CheckErrorPanic(err)
+	return hT
+}
+
 // All tests that make network calls should be marked with this function
 func tSkipShortExternal(t *testing.T) {
 	if testing.Short() {
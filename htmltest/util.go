@@ -2,9 +2,15 @@ package htmltest
 
 import (
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"strings"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"golang.org/x/net/html"
 )
 
 type CertChainErr struct {
@@ -25,6 +31,75 @@ func statusCodeValid(code int) bool {
 	return code == http.StatusPartialContent || code == http.StatusOK
 }
 
+// Classes of network error saved to the refCache
+const (
+	errorClassTimeout         = "timeout"
+	errorClassDNS             = "dns"
+	errorClassConnection      = "connection"
+	errorClassTLS             = "tls"
+	errorClassIncompleteChain = "incomplete-chain"
+	errorClassOther           = "other"
+)
+
+// Which class of network error is err?
+func errorClass(err error) string {
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return errorClassTimeout
+	case errors.As(err, &dnsErr):
+		return errorClassDNS
+	case strings.Contains(err.Error(), "x509:") || strings.Contains(err.Error(), "tls:"):
+		return errorClassTLS
+	case strings.Contains(err.Error(), "dial tcp") || strings.Contains(err.Error(), "connection reset") ||
+		strings.Contains(err.Error(), "EOF"):
+		return errorClassConnection
+	}
+	return errorClassOther
+}
+
+// Status codes servers commonly give when they won't answer a HEAD request
+// but would answer a GET.
+func headUnsupported(code int) bool {
+	return code == http.StatusMethodNotAllowed || code == http.StatusForbidden ||
+		code == http.StatusNotImplemented
+}
+
+// Extract the id/names from an HTML response body. Returns an empty, non-nil,
+// slice when the body isn't HTML or can't be parsed, so the result can still
+// be cached as "fetched".
+func extractResponseHashes(resp *http.Response) []string {
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		return []string{}
+	}
+	hashes, err := htmldoc.ExtractHashes(resp.Body)
+	if err != nil {
+		return []string{}
+	}
+	return hashes
+}
+
+// Is rel one of the space separated values of the node's rel attribute?
+func hasRel(node *html.Node, rel string) bool {
+	for _, value := range strings.Fields(htmldoc.GetAttr(node.Attr, "rel")) {
+		if strings.EqualFold(value, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+func hashInList(hashes []string, hash string) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
 func validateCertChain(cert *x509.Certificate) (err error) {
 	if cert.IssuingCertificateURL == nil {
 		return CertChainErr{cert: cert}
@@ -0,0 +1,150 @@
+package issues
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/output"
+)
+
+// BaselineEntry : Known error recorded in a baseline file. Line numbers are
+// left out so entries survive unrelated edits to a document. Count is the
+// number of times the error occurs.
+type BaselineEntry struct {
+	Rule      string `json:"rule"`
+	Document  string `json:"document,omitempty"`
+	Reference string `json:"reference,omitempty"`
+	Count     int    `json:"count"`
+}
+
+// baselineFile : Layout of the file written by WriteBaseline
+type baselineFile struct {
+	Issues []BaselineEntry `json:"issues"`
+}
+
+// fingerprint : What identifies an issue in a baseline
+type fingerprint struct {
+	rule      string
+	document  string
+	reference string
+}
+
+func (issue *Issue) fingerprint() fingerprint {
+	fp := fingerprint{rule: issue.Rule}
+	if pri := issue.primary(); pri != textNil {
+		fp.document = pri
+	}
+	if issue.Reference != nil {
+		fp.reference = issue.Reference.Path
+	}
+	return fp
+}
+
+func (entry *BaselineEntry) fingerprint() fingerprint {
+	return fingerprint{entry.Rule, entry.Document, entry.Reference}
+}
+
+// Entries for the given fingerprint counts, sorted by document, rule then
+// reference.
+func baselineEntries(counts map[fingerprint]int) []BaselineEntry {
+	entries := make([]BaselineEntry, 0, len(counts))
+	for fp, count := range counts {
+		entries = append(entries, BaselineEntry{fp.rule, fp.document, fp.reference, count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Document != b.Document {
+			return a.Document < b.Document
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Reference < b.Reference
+	})
+	return entries
+}
+
+// WriteBaseline : Write every error level issue in the store, including those
+// already in a loaded baseline but not those suppressed by comments, to a
+// baseline file at the given path. Returns the number of errors written.
+func (iS *IssueStore) WriteBaseline(path string) int {
+	counts := make(map[fingerprint]int)
+	total := 0
+	for _, issue := range iS.Issues(LevelError) {
+		if issue.Suppressed {
+			continue
+		}
+		counts[issue.fingerprint()]++
+		total++
+	}
+
+	os.MkdirAll(filepath.Dir(path), 0777)
+	f, err := os.Create(path)
+	output.CheckErrorPanic(err)
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(&baselineFile{Issues: baselineEntries(counts)})
+	output.CheckErrorPanic(err)
+	return total
+}
+
+// LoadBaseline : Read the baseline file at the given path. Errors added to the
+// store afterwards which match an entry, up to its count, are known: they're
+// marked Baselined, aren't counted and aren't printed.
+func (iS *IssueStore) LoadBaseline(path string) error {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var file baselineFile
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return err
+	}
+
+	iS.storeMutex.Lock()
+	defer iS.storeMutex.Unlock()
+	iS.baseline = make(map[fingerprint]int)
+	for _, entry := range file.Issues {
+		iS.baseline[entry.fingerprint()] += entry.Count
+	}
+	return nil
+}
+
+// BaselineFixed : Entries of the loaded baseline not matched by an issue,
+// errors which have since been fixed. Count is the number of occurrences
+// fixed. Only entries for the given documents, or for no document, are
+// returned.
+func (iS *IssueStore) BaselineFixed(documents []*htmldoc.Document) []BaselineEntry {
+	tested := make(map[string]bool)
+	for _, doc := range documents {
+		tested[doc.SitePath] = true
+	}
+
+	iS.storeMutex.RLock()
+	defer iS.storeMutex.RUnlock()
+	fixed := make(map[fingerprint]int)
+	for fp, count := range iS.baseline {
+		if count > 0 && (fp.document == "" || tested[fp.document]) {
+			fixed[fp] = count
+		}
+	}
+	return baselineEntries(fixed)
+}
+
+// CountBaselined : Number of issues in the store matched by the loaded
+// baseline.
+func (iS *IssueStore) CountBaselined() int {
+	count := 0
+	for _, issue := range iS.issues {
+		if issue.Baselined {
+			count++
+		}
+	}
+	return count
+}
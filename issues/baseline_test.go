@@ -0,0 +1,78 @@
+package issues
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+	"github.com/wjdp/htmltest/htmldoc"
+)
+
+func TestIssueStoreBaseline(t *testing.T) {
+	// passes for known errors matched up to their count and fixed ones listed
+	baselinePath := path.Join(t.TempDir(), "baseline.json")
+	doc := htmldoc.Document{SitePath: "dir/page.html"}
+	missing := htmldoc.Reference{Document: &doc, Path: "missing.html", Line: 3}
+	gone := htmldoc.Reference{Document: &doc, Path: "gone.html", Line: 4}
+
+	iS := NewIssueStore(LevelNone, false)
+	iS.AddIssue(Issue{Level: LevelError, Message: "target does not exist", Rule: RuleLinkTargetMissing, Reference: &missing})
+	iS.AddIssue(Issue{Level: LevelError, Message: "target does not exist", Rule: RuleLinkTargetMissing, Reference: &missing})
+	iS.AddIssue(Issue{Level: LevelError, Message: "target does not exist", Rule: RuleLinkTargetMissing, Reference: &gone})
+	iS.AddIssue(Issue{Level: LevelWarning, Message: "warn", Document: &doc})
+	assert.Equals(t, "errors written", iS.WriteBaseline(baselinePath), 3)
+
+	// Same errors on different lines, one fewer gone.html and a new one
+	moved := htmldoc.Reference{Document: &doc, Path: "missing.html", Line: 10}
+	other := htmldoc.Reference{Document: &doc, Path: "other.html", Line: 11}
+	iS = NewIssueStore(LevelNone, false)
+	assert.Equals(t, "load error", iS.LoadBaseline(baselinePath), nil)
+	iS.AddIssue(Issue{Level: LevelError, Message: "target does not exist", Rule: RuleLinkTargetMissing, Reference: &moved})
+	iS.AddIssue(Issue{Level: LevelError, Message: "target does not exist", Rule: RuleLinkTargetMissing, Reference: &moved})
+	iS.AddIssue(Issue{Level: LevelError, Message: "target does not exist", Rule: RuleLinkTargetMissing, Reference: &moved})
+	iS.AddIssue(Issue{Level: LevelError, Message: "target does not exist", Rule: RuleLinkTargetMissing, Reference: &other})
+	assert.Equals(t, "error count", iS.Count(LevelError), 2)
+	assert.Equals(t, "baselined count", iS.CountBaselined(), 2)
+	fixed := iS.BaselineFixed([]*htmldoc.Document{&doc})
+	assert.Equals(t, "fixed count", len(fixed), 1)
+	assert.Equals(t, "fixed", fixed[0], BaselineEntry{
+		Rule: RuleLinkTargetMissing, Document: "dir/page.html", Reference: "gone.html", Count: 1,
+	})
+	assert.Equals(t, "fixed in untested documents", len(iS.BaselineFixed(nil)), 0)
+}
+
+func TestIssueStoreBaselineMissing(t *testing.T) {
+	iS := NewIssueStore(LevelNone, false)
+	err := iS.LoadBaseline(path.Join(t.TempDir(), "nope.json"))
+	assert.NotEquals(t, "load error", err, nil)
+}
+
+func TestIssueStoreBaselineFormat(t *testing.T) {
+	// entries are sorted and don't have line numbers
+	baselinePath := path.Join(t.TempDir(), "baseline.json")
+	doc := htmldoc.Document{SitePath: "page.html"}
+	ref := htmldoc.Reference{Document: &doc, Path: "b.png", Line: 2}
+	iS := NewIssueStore(LevelNone, false)
+	iS.AddIssue(Issue{Level: LevelError, Message: "alt attribute missing", Rule: RuleImgAltMissing, Reference: &ref})
+	iS.AddIssue(Issue{Level: LevelError, Message: "favicon missing", Rule: RuleFaviconMissing})
+	iS.WriteBaseline(baselinePath)
+
+	b, err := ioutil.ReadFile(baselinePath)
+	assert.Equals(t, "file error", err, nil)
+	assert.Equals(t, "baseline", string(b), `{
+  "issues": [
+    {
+      "rule": "favicon/missing",
+      "count": 1
+    },
+    {
+      "rule": "img/alt-missing",
+      "document": "page.html",
+      "reference": "b.png",
+      "count": 1
+    }
+  ]
+}
+`)
+}
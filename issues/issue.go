@@ -2,6 +2,7 @@ package issues
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/wjdp/htmltest/htmldoc"
@@ -22,14 +23,69 @@ const (
 	textNil string = "<nil>"
 )
 
+// Names of the levels, as used in config files and machine readable output
+var levelNames = map[int]string{
+	LevelNone:    "none",
+	LevelError:   "error",
+	LevelWarning: "warning",
+	LevelInfo:    "info",
+	LevelDebug:   "debug",
+}
+
+// LevelName : Name of the given level, "unknown" if it isn't one of the consts.
+func LevelName(level int) string {
+	if name, ok := levelNames[level]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseLevel : Level for a name such as "warning", case insensitive. ok is
+// false when the name isn't known.
+func ParseLevel(name string) (int, bool) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, true
+		}
+	}
+	return 0, false
+}
+
 // Issue struct representing a single issue with a document.
-// Set all except Document and Reference, set one or the other.
+// Set all except Document and Reference, set one or the other. Rule,
+// StatusCode and URL are optional, they're given in machine readable output.
+// Debug and info issues don't have a rule.
 type Issue struct {
-	Level     int                // Level of the issue, use the consts at the top of this file
-	Document  *htmldoc.Document  // Document this issue pertains to
-	Reference *htmldoc.Reference // Reference this issue pertains to
-	Message   string             // Error message, keep short
-	store     *IssueStore        // Internal ref to the store this issue is owned by
+	Level      int                // Level of the issue, use the consts at the top of this file
+	Document   *htmldoc.Document  // Document this issue pertains to
+	Reference  *htmldoc.Reference // Reference this issue pertains to
+	Message    string             // Error message, keep short
+	Rule       string             // Rule code of the check raising the issue, use the consts in rules.go
+	StatusCode int                // HTTP status code of the reference's target, if fetched
+	URL        string             // URL checked for the reference, if different to Reference.Path
+	Baselined  bool               // Set by the store when the issue is in a loaded baseline
+	Suppressed bool               // Set by the store when a comment in the document turns the rule off
+	store      *IssueStore        // Internal ref to the store this issue is owned by
+}
+
+// Is the issue left out of counts and output? True for known issues, those in
+// a baseline or turned off by a comment.
+func (issue *Issue) excluded() bool {
+	return issue.Baselined || issue.Suppressed
+}
+
+// Has a comment in the issue's document turned off its rule?
+func (issue *Issue) suppressed() bool {
+	if issue.Rule == "" {
+		return false
+	}
+	if issue.Reference != nil && issue.Reference.Document != nil {
+		return issue.Reference.Document.Suppressed(issue.Reference.Node, issue.Rule)
+	}
+	if issue.Document != nil {
+		return issue.Document.Suppressed(nil, issue.Rule)
+	}
+	return false
 }
 
 // Textual description of the primary item in the issue
@@ -50,12 +106,22 @@ func (issue *Issue) secondary() string {
 	return textNil
 }
 
+// Textual description of where the issue is, the primary item followed by the
+// reference's line and column when they're known, as path:line:col
+func (issue *Issue) location() string {
+	pri := issue.primary()
+	if pri != textNil && issue.Reference != nil && issue.Reference.Line > 0 {
+		return fmt.Sprintf("%v:%d:%d", pri, issue.Reference.Line, issue.Reference.Column)
+	}
+	return pri
+}
+
 // Text to print
 func (issue *Issue) text() string {
 	pri := issue.primary()
 	sec := issue.secondary()
 	if pri != textNil || sec != textNil {
-		return fmt.Sprintf("%v --- %v --> %v", issue.Message, issue.primary(),
+		return fmt.Sprintf("%v --- %v --> %v", issue.Message, issue.location(),
 			issue.secondary())
 	}
 	return issue.Message
@@ -63,7 +129,7 @@ func (issue *Issue) text() string {
 
 // Print to stdout with optional colour (controlled by color.NoColor - see main())
 func (issue *Issue) print(force bool, prefix string) {
-	if (issue.Level < issue.store.logLevel) && !force {
+	if (issue.Level < issue.store.logLevel || issue.excluded()) && !force {
 		return
 	}
 
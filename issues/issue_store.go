@@ -18,6 +18,8 @@ type IssueStore struct {
 	printImmediately bool                // Print issues when added
 	issues           []*Issue            // All issues
 	issuesByDoc      map[string][]*Issue // Issues by Document.SitePath
+	ruleLevels       map[string]int      // Levels overriding those given by checks, by rule code
+	baseline         map[fingerprint]int // Known errors yet to be matched, from LoadBaseline
 	storeMutex       *sync.RWMutex       // Mutex to control access to stores
 	byteLog          []byte              // Bytestream of issues, built when issues are added and written to disk at end
 }
@@ -29,15 +31,51 @@ func NewIssueStore(logLevel int, printImmediately bool) IssueStore {
 	iS.issuesByDoc = make(map[string][]*Issue)
 	iS.storeMutex = &sync.RWMutex{}
 	iS.byteLog = make([]byte, 0)
+	iS.ruleLevels = make(map[string]int)
 	return iS
 }
 
+// SetRuleLevels : Report issues with the given rule codes at the given levels
+// rather than the level set by the check. Issues of rules set to LevelNone
+// are dropped. Call before adding issues.
+func (iS *IssueStore) SetRuleLevels(levels map[string]int) {
+	iS.ruleLevels = levels
+}
+
+// SetPrintImmediately : Print issues when they're added, or not. Used once
+// every document's issues have been printed, so issues added later aren't
+// missed.
+func (iS *IssueStore) SetPrintImmediately(printImmediately bool) {
+	iS.storeMutex.Lock()
+	iS.printImmediately = printImmediately
+	iS.storeMutex.Unlock()
+}
+
+// RuleEnabled : Will issues with the given rule code be kept? For checks that
+// do more than add an issue.
+func (iS *IssueStore) RuleEnabled(rule string) bool {
+	return iS.ruleLevels[rule] != LevelNone
+}
+
 // AddIssue : Add an issue to the issue store, thread safe.
 func (iS *IssueStore) AddIssue(issue Issue) {
+	if level, ok := iS.ruleLevels[issue.Rule]; ok && issue.Rule != "" {
+		if level == LevelNone {
+			return
+		}
+		issue.Level = level
+	}
+
 	issue.store = iS // Set ref to issue store in issue
+	issue.Suppressed = issue.suppressed()
 
 	iS.storeMutex.Lock()
 
+	if fp := issue.fingerprint(); issue.Level == LevelError && !issue.Suppressed && iS.baseline[fp] > 0 {
+		iS.baseline[fp]--
+		issue.Baselined = true
+	}
+
 	iS.issues = append(iS.issues, &issue)
 	iS.issuesByDoc[issue.primary()] = append(
 		iS.issuesByDoc[issue.primary()], &issue)
@@ -45,7 +83,7 @@ func (iS *IssueStore) AddIssue(issue Issue) {
 	if iS.printImmediately || issue.primary() == textNil {
 		issue.print(false, "")
 	}
-	if issue.Level >= iS.logLevel {
+	if issue.Level >= iS.logLevel && !issue.excluded() {
 		// Build byte slice to write out at the end
 		iS.byteLog = append(iS.byteLog, []byte(issue.text()+"\n")...)
 	}
@@ -54,24 +92,39 @@ func (iS *IssueStore) AddIssue(issue Issue) {
 }
 
 // Count : Counts the number of issues in the store at, or above, the given
-// level.
+// level. Issues in a loaded baseline or suppressed by comments aren't counted.
 func (iS *IssueStore) Count(level int) int {
 	count := 0
 	for _, issue := range iS.issues {
-		if issue.Level >= level {
+		if issue.Level >= level && !issue.excluded() {
 			count++
 		}
 	}
 	return count
 }
 
+// Issues : Return the issues in the store at, or above, the given level, in
+// the order they were added.
+func (iS *IssueStore) Issues(level int) []*Issue {
+	iS.storeMutex.RLock()
+	defer iS.storeMutex.RUnlock()
+	filtered := make([]*Issue, 0)
+	for _, issue := range iS.issues {
+		if issue.Level >= level {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
+
 // CountByDoc : Count the number of issues in the store at, or above, the given
-// level pertaining to the provided document. Thread safe.
+// level pertaining to the provided document, except those in a loaded
+// baseline or suppressed by comments. Thread safe.
 func (iS *IssueStore) CountByDoc(level int, doc *htmldoc.Document) int {
 	iS.storeMutex.RLock()
 	count := 0
 	for _, issue := range iS.issuesByDoc[doc.SitePath] {
-		if issue.Level >= level {
+		if issue.Level >= level && !issue.excluded() {
 			count++
 		}
 	}
@@ -117,6 +170,18 @@ func (iS *IssueStore) WriteLog(path string) {
 	output.CheckErrorPanic(err)
 }
 
+// CountSuppressed : Number of issues in the store turned off by comments in
+// their documents.
+func (iS *IssueStore) CountSuppressed() int {
+	count := 0
+	for _, issue := range iS.issues {
+		if issue.Suppressed {
+			count++
+		}
+	}
+	return count
+}
+
 // DumpIssues : Dump all issues to stdout, called by test helpers when issue
 // asserts fail.
 func (iS *IssueStore) DumpIssues(force bool) {
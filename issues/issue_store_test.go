@@ -32,6 +32,32 @@ func TestIssueStoreCount(t *testing.T) {
 	assert.Equals(t, "issue count", iS.Count(LevelInfo), 3)
 }
 
+func TestIssueStoreIssues(t *testing.T) {
+	iS := NewIssueStore(LevelNone, false)
+	iS.AddIssue(Issue{Level: LevelError, Message: "error one"})
+	iS.AddIssue(Issue{Level: LevelInfo, Message: "notice"})
+	iS.AddIssue(Issue{Level: LevelError, Message: "error two"})
+	errors := iS.Issues(LevelError)
+	assert.Equals(t, "issue count", len(errors), 2)
+	assert.Equals(t, "first issue", errors[0].Message, "error one")
+	assert.Equals(t, "second issue", errors[1].Message, "error two")
+	assert.Equals(t, "issue count", len(iS.Issues(LevelDebug)), 3)
+}
+
+func TestIssueStoreRuleLevels(t *testing.T) {
+	iS := NewIssueStore(LevelNone, false)
+	iS.SetRuleLevels(map[string]int{RuleImgAltEmpty: LevelNone, RuleLinkHashMissing: LevelWarning})
+	iS.AddIssue(Issue{Level: LevelError, Message: "alt text empty", Rule: RuleImgAltEmpty})
+	iS.AddIssue(Issue{Level: LevelError, Message: "hash does not exist", Rule: RuleLinkHashMissing})
+	iS.AddIssue(Issue{Level: LevelError, Message: "alt attribute missing", Rule: RuleImgAltMissing})
+	assert.Equals(t, "issue count", len(iS.Issues(LevelDebug)), 2)
+	assert.Equals(t, "error count", iS.Count(LevelError), 1)
+	assert.Equals(t, "warning count", iS.Count(LevelWarning), 2)
+	assert.IsFalse(t, "alt-empty enabled", iS.RuleEnabled(RuleImgAltEmpty))
+	assert.IsTrue(t, "hash-missing enabled", iS.RuleEnabled(RuleLinkHashMissing))
+	assert.IsTrue(t, "alt-missing enabled", iS.RuleEnabled(RuleImgAltMissing))
+}
+
 func TestIssueStoreMessageMatchCount(t *testing.T) {
 	iS := NewIssueStore(LevelNone, false)
 	iS.AddIssue(Issue{Level: LevelError, Message: "error one"})
@@ -41,6 +41,48 @@ func TestIssueSecondary(t *testing.T) {
 	assert.Equals(t, "issue1 secondary", issue1.secondary(), "http://example.com")
 }
 
+func TestIssueLocation(t *testing.T) {
+	doc := htmldoc.Document{
+		SitePath: "dir/doc.html",
+	}
+	issue0 := Issue{Document: &doc}
+	assert.Equals(t, "issue0 location", issue0.location(), "dir/doc.html")
+
+	ref := htmldoc.Reference{
+		Document: &doc,
+		Line:     12,
+		Column:   5,
+	}
+	issue1 := Issue{Reference: &ref}
+	assert.Equals(t, "issue1 location", issue1.location(), "dir/doc.html:12:5")
+
+	issue2 := Issue{Reference: &htmldoc.Reference{Line: 12, Column: 5}}
+	assert.Equals(t, "issue2 location", issue2.location(), textNil)
+}
+
+func ExampleIssue_print_position() {
+	doc := htmldoc.Document{
+		SitePath: "dir/doc.html",
+	}
+	ref := htmldoc.Reference{
+		Document: &doc,
+		Path:     "missing.html",
+		Line:     12,
+		Column:   5,
+	}
+
+	issue := Issue{
+		Level:     LevelError,
+		Reference: &ref,
+		store:     &IssueStore{logLevel: LevelError},
+		Message:   "target does not exist",
+	}
+	issue.print(false, "")
+
+	// Output:
+	// target does not exist --- dir/doc.html:12:5 --> missing.html
+}
+
 func ExampleIssue_print_logLevel() {
 	doc := htmldoc.Document{
 		SitePath: "dir/doc.html",
@@ -136,3 +178,20 @@ func ExampleIssue_print_logAll() {
 	// test4 --- dir/doc.html --> <nil>
 
 }
+
+func TestLevelName(t *testing.T) {
+	assert.Equals(t, "error", LevelName(LevelError), "error")
+	assert.Equals(t, "debug", LevelName(LevelDebug), "debug")
+	assert.Equals(t, "unknown", LevelName(42), "unknown")
+}
+
+func TestParseLevel(t *testing.T) {
+	level, ok := ParseLevel("warning")
+	assert.IsTrue(t, "warning ok", ok)
+	assert.Equals(t, "warning", level, LevelWarning)
+	level, ok = ParseLevel("Info")
+	assert.IsTrue(t, "Info ok", ok)
+	assert.Equals(t, "Info", level, LevelInfo)
+	_, ok = ParseLevel("fatal")
+	assert.IsFalse(t, "fatal ok", ok)
+}
@@ -0,0 +1,106 @@
+package issues
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/wjdp/htmltest/output"
+)
+
+// jsonReport : Layout of the report written by WriteJSON
+type jsonReport struct {
+	Totals jsonTotals  `json:"totals"`
+	Issues []jsonIssue `json:"issues"`
+}
+
+// jsonTotals : Run level totals, issue counts are of all issues regardless of
+// log level. Issues in a loaded baseline are only counted in Baselined, those
+// turned off by comments only in Suppressed.
+type jsonTotals struct {
+	Documents  int  `json:"documents"`
+	Errors     int  `json:"errors"`
+	Warnings   int  `json:"warnings"`
+	Info       int  `json:"info"`
+	Debug      int  `json:"debug"`
+	Baselined  int  `json:"baselined,omitempty"`
+	Suppressed int  `json:"suppressed,omitempty"`
+	Passed     bool `json:"passed"`
+}
+
+type jsonIssue struct {
+	Level      string `json:"level"`
+	Rule       string `json:"rule,omitempty"`
+	Message    string `json:"message"`
+	Document   string `json:"document,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	Column     int    `json:"column,omitempty"`
+	Reference  string `json:"reference,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	Attribute  string `json:"attribute,omitempty"`
+	URL        string `json:"url,omitempty"`
+	StatusCode int    `json:"status,omitempty"`
+	Baseline   bool   `json:"baseline,omitempty"`
+	Suppressed bool   `json:"suppressed,omitempty"`
+}
+
+// Structured form of the issue for the JSON report
+func (issue *Issue) jsonIssue() jsonIssue {
+	jI := jsonIssue{
+		Level:      LevelName(issue.Level),
+		Rule:       issue.Rule,
+		Message:    issue.Message,
+		URL:        issue.URL,
+		StatusCode: issue.StatusCode,
+		Baseline:   issue.Baselined,
+		Suppressed: issue.Suppressed,
+	}
+	if pri := issue.primary(); pri != textNil {
+		jI.Document = pri
+	}
+	if ref := issue.Reference; ref != nil {
+		jI.Reference = ref.Path
+		jI.Line = ref.Line
+		jI.Column = ref.Column
+		jI.Attribute = ref.Attr
+		if ref.Node != nil {
+			jI.Tag = ref.Node.Data
+		}
+		if jI.URL == "" && ref.URL != nil {
+			jI.URL = ref.URLString()
+		}
+	}
+	return jI
+}
+
+// WriteJSON : Write a JSON report of the issue store to the given path. Issues
+// are filtered by logLevel given in NewIssueStore, totals are not.
+// documentCount is the number of documents tested.
+func (iS *IssueStore) WriteJSON(path string, documentCount int) {
+	report := jsonReport{
+		Totals: jsonTotals{
+			Documents:  documentCount,
+			Errors:     iS.Count(LevelError),
+			Warnings:   iS.Count(LevelWarning) - iS.Count(LevelError),
+			Info:       iS.Count(LevelInfo) - iS.Count(LevelWarning),
+			Debug:      iS.Count(LevelDebug) - iS.Count(LevelInfo),
+			Baselined:  iS.CountBaselined(),
+			Suppressed: iS.CountSuppressed(),
+			Passed:     iS.Count(LevelError) == 0,
+		},
+		Issues: make([]jsonIssue, 0),
+	}
+	for _, issue := range iS.Issues(iS.logLevel) {
+		report.Issues = append(report.Issues, issue.jsonIssue())
+	}
+
+	os.MkdirAll(filepath.Dir(path), 0777)
+	f, err := os.Create(path)
+	output.CheckErrorPanic(err)
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(&report)
+	output.CheckErrorPanic(err)
+}
@@ -0,0 +1,72 @@
+package issues
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+	"github.com/wjdp/htmltest/htmldoc"
+	"golang.org/x/net/html"
+)
+
+func TestIssueStoreWriteJSON(t *testing.T) {
+	// passes for report written using LogLevel, with totals of all issues
+	JSONFILE := "issue-store-test.json"
+	iS := NewIssueStore(LevelWarning, false)
+	doc := htmldoc.Document{
+		SitePath: "dir/page.html",
+	}
+	u, _ := url.Parse("http://example.com/missing")
+	ref := htmldoc.Reference{
+		Document: &doc,
+		Node:     &html.Node{Type: html.ElementNode, Data: "a"},
+		Path:     "http://example.com/missing",
+		URL:      u,
+		Attr:     "href",
+		Line:     3,
+		Column:   7,
+	}
+	iS.AddIssue(Issue{
+		Level:      LevelError,
+		Message:    "Non-OK status: 404",
+		Reference:  &ref,
+		StatusCode: 404,
+	})
+	iS.AddIssue(Issue{Level: LevelWarning, Message: "warn", Document: &doc})
+	iS.AddIssue(Issue{Level: LevelDebug, Message: "hitting", Reference: &ref})
+
+	iS.WriteJSON(JSONFILE, 3)
+	jsonBytes, err := ioutil.ReadFile(JSONFILE)
+	assert.Equals(t, "file error", err, nil)
+
+	var report jsonReport
+	err = json.Unmarshal(jsonBytes, &report)
+	assert.Equals(t, "json error", err, nil)
+	assert.Equals(t, "totals", report.Totals, jsonTotals{
+		Documents: 3, Errors: 1, Warnings: 1, Debug: 1, Passed: false,
+	})
+	assert.Equals(t, "issue count", len(report.Issues), 2)
+	assert.Equals(t, "first issue", report.Issues[0], jsonIssue{
+		Level:      "error",
+		Message:    "Non-OK status: 404",
+		Document:   "dir/page.html",
+		Line:       3,
+		Column:     7,
+		Reference:  "http://example.com/missing",
+		Tag:        "a",
+		Attribute:  "href",
+		URL:        "http://example.com/missing",
+		StatusCode: 404,
+	})
+	assert.Equals(t, "second issue", report.Issues[1], jsonIssue{
+		Level:    "warning",
+		Message:  "warn",
+		Document: "dir/page.html",
+	})
+
+	removeErr := os.Remove(JSONFILE)
+	assert.Equals(t, "file error", removeErr, nil)
+}
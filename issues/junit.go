@@ -0,0 +1,112 @@
+package issues
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+
+	"github.com/wjdp/htmltest/htmldoc"
+	"github.com/wjdp/htmltest/output"
+)
+
+// Name of the suite, and the testcase holding errors without a document
+const junitSuiteName string = "htmltest"
+
+// junitSuites : Layout of the report written by WriteJUnit
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Skipped   *junitSkipped  `xml:"skipped,omitempty"`
+	Failures  []junitFailure `xml:"failure"`
+}
+
+type junitSkipped struct{}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Failure for an error level issue
+func (issue *Issue) junitFailure() junitFailure {
+	return junitFailure{
+		Message: issue.Message,
+		Type:    LevelName(issue.Level),
+		Text:    issue.text(),
+	}
+}
+
+// WriteJUnit : Write a JUnit XML report of the issue store to the given path.
+// Each of documents is a testcase, failed by its error level issues not in a
+// loaded baseline or suppressed, or skipped if the document is ignored.
+// Errors not tied to a document fail an extra "htmltest" testcase.
+func (iS *IssueStore) WriteJUnit(path string, documents []*htmldoc.Document) {
+	suite := junitSuite{Name: junitSuiteName, Cases: make([]junitTestCase, 0)}
+
+	iS.storeMutex.RLock()
+	for _, doc := range documents {
+		testCase := junitTestCase{Name: doc.SitePath, ClassName: junitSuiteName}
+		if doc.IgnoreTest {
+			testCase.Skipped = &junitSkipped{}
+			suite.Skipped++
+		}
+		for _, issue := range iS.issuesByDoc[doc.SitePath] {
+			if issue.Level == LevelError && !issue.excluded() {
+				testCase.Failures = append(testCase.Failures, issue.junitFailure())
+			}
+		}
+		if len(testCase.Failures) > 0 {
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	runCase := junitTestCase{Name: junitSuiteName, ClassName: junitSuiteName}
+	for _, issue := range iS.issuesByDoc[textNil] {
+		if issue.Level == LevelError && !issue.excluded() {
+			runCase.Failures = append(runCase.Failures, issue.junitFailure())
+		}
+	}
+	if len(runCase.Failures) > 0 {
+		suite.Failures++
+		suite.Cases = append(suite.Cases, runCase)
+	}
+	iS.storeMutex.RUnlock()
+	suite.Tests = len(suite.Cases)
+
+	report := junitSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suites:   []junitSuite{suite},
+	}
+
+	os.MkdirAll(filepath.Dir(path), 0777)
+	f, err := os.Create(path)
+	output.CheckErrorPanic(err)
+	defer f.Close()
+
+	_, err = f.WriteString(xml.Header)
+	output.CheckErrorPanic(err)
+	encoder := xml.NewEncoder(f)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(&report)
+	output.CheckErrorPanic(err)
+}
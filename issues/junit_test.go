@@ -0,0 +1,61 @@
+package issues
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+	"github.com/wjdp/htmltest/htmldoc"
+)
+
+func TestIssueStoreWriteJUnit(t *testing.T) {
+	// passes for a testcase per document, failed by errors, skipped if ignored
+	JUNITFILE := "issue-store-test.xml"
+	iS := NewIssueStore(LevelNone, false)
+	docOK := htmldoc.Document{SitePath: "ok.html"}
+	docBroken := htmldoc.Document{SitePath: "broken.html"}
+	docIgnored := htmldoc.Document{SitePath: "ignored.html", IgnoreTest: true}
+	iS.AddIssue(Issue{Level: LevelWarning, Message: "advisory", Document: &docOK})
+	iS.AddIssue(Issue{Level: LevelError, Message: "target does not exist", Document: &docBroken})
+	iS.AddIssue(Issue{Level: LevelError, Message: "alt text empty", Document: &docBroken})
+
+	iS.WriteJUnit(JUNITFILE, []*htmldoc.Document{&docOK, &docBroken, &docIgnored})
+	xmlBytes, err := ioutil.ReadFile(JUNITFILE)
+	assert.Equals(t, "file error", err, nil)
+	xmlString := string(xmlBytes)
+
+	assert.IsTrue(t, "suite totals", strings.Contains(xmlString,
+		`<testsuite name="htmltest" tests="3" failures="1" skipped="1">`))
+	assert.IsTrue(t, "ok testcase", strings.Contains(xmlString,
+		`<testcase name="ok.html" classname="htmltest"></testcase>`))
+	assert.IsTrue(t, "failure", strings.Contains(xmlString,
+		`<failure message="target does not exist" type="error">target does not exist --- broken.html --&gt; &lt;nil&gt;</failure>`))
+	assert.Equals(t, "failure count", strings.Count(xmlString, "<failure "), 2)
+	assert.IsTrue(t, "skipped", strings.Contains(xmlString,
+		`<testcase name="ignored.html" classname="htmltest">`+"\n      <skipped></skipped>"))
+
+	removeErr := os.Remove(JUNITFILE)
+	assert.Equals(t, "file error", removeErr, nil)
+}
+
+func TestIssueStoreWriteJUnitNoDocument(t *testing.T) {
+	// passes for errors without a document failing a run level testcase
+	JUNITFILE := "issue-store-test-nodoc.xml"
+	iS := NewIssueStore(LevelNone, false)
+	iS.AddIssue(Issue{Level: LevelError, Message: "too many redirects"})
+
+	iS.WriteJUnit(JUNITFILE, []*htmldoc.Document{})
+	xmlBytes, err := ioutil.ReadFile(JUNITFILE)
+	assert.Equals(t, "file error", err, nil)
+	xmlString := string(xmlBytes)
+
+	assert.IsTrue(t, "run testcase", strings.Contains(xmlString,
+		`<testcase name="htmltest" classname="htmltest">`))
+	assert.IsTrue(t, "failure", strings.Contains(xmlString,
+		`<failure message="too many redirects" type="error">too many redirects</failure>`))
+
+	removeErr := os.Remove(JUNITFILE)
+	assert.Equals(t, "file error", removeErr, nil)
+}
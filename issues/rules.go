@@ -0,0 +1,181 @@
+package issues
+
+import "sort"
+
+// Rule codes, a stable identifier for each kind of issue the checks raise
+const (
+	RuleDoctypeMissing  string = "doctype/missing"
+	RuleDoctypeNotHTML5 string = "doctype/not-html5"
+
+	RuleRefInvalid  string = "reference/invalid"
+	RuleRefBlank    string = "reference/blank"
+	RuleRefNotHTTPS string = "reference/not-https"
+
+	RuleLinkHrefMissing        string = "link/href-missing"
+	RuleLinkHrefBlank          string = "link/href-blank"
+	RuleLinkHashEmpty          string = "link/hash-empty"
+	RuleLinkHashMissing        string = "link/hash-missing"
+	RuleLinkTargetMissing      string = "link/target-missing"
+	RuleLinkDirectoryNoSlash   string = "link/directory-no-slash"
+	RuleLinkDirectoryNoIndex   string = "link/directory-no-index"
+	RuleExternalStatus         string = "external/status"
+	RuleExternalCanonical      string = "external/canonical-status"
+	RuleExternalAccepted       string = "external/accepted-status"
+	RuleExternalTimeout        string = "external/timeout"
+	RuleExternalError          string = "external/error"
+	RuleExternalCertChain      string = "external/incomplete-chain"
+	RuleExternalHashMissing    string = "external/hash-missing"
+	RuleExternalRedirect       string = "external/permanent-redirect"
+	RuleExternalDowngrade      string = "external/redirect-downgrade"
+	RuleExternalRedirectsLimit string = "external/too-many-redirects"
+	RuleMailtoEmpty            string = "mailto/empty"
+	RuleMailtoInvalid          string = "mailto/invalid"
+	RuleTelEmpty               string = "tel/empty"
+
+	RuleImgAltMissing    string = "img/alt-missing"
+	RuleImgAltEmpty      string = "img/alt-empty"
+	RuleImgAltWhitespace string = "img/alt-whitespace"
+	RuleImgSrcMissing    string = "img/src-missing"
+	RuleImgSrcEmpty      string = "img/src-empty"
+	RuleImgUsemapInvalid string = "img/usemap-invalid"
+	RuleImgUsemapEmpty   string = "img/usemap-empty"
+	RuleImgUsemapNested  string = "img/usemap-nested"
+
+	RuleSrcsetEmpty        string = "srcset/empty"
+	RuleSrcsetInvalid      string = "srcset/invalid-descriptor"
+	RuleSrcsetMixed        string = "srcset/mixed-descriptors"
+	RuleSrcsetSizesMissing string = "srcset/sizes-missing"
+
+	RuleScriptSrcEmpty       string = "script/src-empty"
+	RuleScriptContentMissing string = "script/content-missing"
+
+	RuleMetaRefreshQuoted  string = "meta/refresh-quoted"
+	RuleMetaRefreshBlank   string = "meta/refresh-blank"
+	RuleMetaRefreshInvalid string = "meta/refresh-invalid"
+	RuleMetaRefreshMissing string = "meta/refresh-missing"
+
+	RuleOpenGraphMissing     string = "opengraph/missing"
+	RuleOpenGraphEmpty       string = "opengraph/empty"
+	RuleOpenGraphNotAbsolute string = "opengraph/not-absolute"
+
+	RuleHeadTitleMissing       string = "head/title-missing"
+	RuleHeadTitleEmpty         string = "head/title-empty"
+	RuleHeadTitleMultiple      string = "head/title-multiple"
+	RuleHeadDescriptionMissing string = "head/description-missing"
+	RuleHeadDescriptionLength  string = "head/description-length"
+	RuleHeadCanonicalMultiple  string = "head/canonical-multiple"
+	RuleHeadCharsetMissing     string = "head/charset-missing"
+	RuleHeadCharsetLate        string = "head/charset-late"
+	RuleHeadLangMissing        string = "head/lang-missing"
+
+	RuleDuplicateTitle       string = "duplicate/title"
+	RuleDuplicateDescription string = "duplicate/description"
+	RuleDuplicateCanonical   string = "duplicate/canonical"
+
+	RuleHreflangInvalid        string = "hreflang/invalid"
+	RuleHreflangDefaultMissing string = "hreflang/x-default-missing"
+	RuleHreflangNotReciprocal  string = "hreflang/not-reciprocal"
+	RuleHreflangCanonicalised  string = "hreflang/target-canonicalised"
+
+	RuleGraphOrphan  string = "graph/orphan"
+	RuleGraphTooDeep string = "graph/too-deep"
+
+	RuleFaviconMissing string = "favicon/missing"
+)
+
+// Description of each rule, keyed by rule code
+var ruleDescriptions = map[string]string{
+	RuleDoctypeMissing:  "Document has no doctype",
+	RuleDoctypeNotHTML5: "Doctype isn't the HTML5 doctype",
+
+	RuleRefInvalid:  "Reference can't be parsed as a URL",
+	RuleRefBlank:    "Attribute holding a reference is blank",
+	RuleRefNotHTTPS: "Reference isn't to an HTTPS target",
+
+	RuleLinkHrefMissing:        "Link has no href attribute",
+	RuleLinkHrefBlank:          "Link has a blank href attribute",
+	RuleLinkHashEmpty:          "Link to an empty hash",
+	RuleLinkHashMissing:        "Hash not found in the target document",
+	RuleLinkTargetMissing:      "Internal link target doesn't exist",
+	RuleLinkDirectoryNoSlash:   "Link to a directory lacks a trailing slash",
+	RuleLinkDirectoryNoIndex:   "Link to a directory without an index document",
+	RuleExternalStatus:         "External link gave a non-OK status",
+	RuleExternalCanonical:      "Canonical link gave a non-OK status",
+	RuleExternalAccepted:       "External link gave a status allowed by AcceptStatusCodes",
+	RuleExternalTimeout:        "External request exceeded ExternalTimeout",
+	RuleExternalError:          "External request failed, e.g. the host couldn't be found",
+	RuleExternalCertChain:      "External host sends an incomplete certificate chain",
+	RuleExternalHashMissing:    "Hash not found on the external page",
+	RuleExternalRedirect:       "External link permanently redirects, update it to the final URL",
+	RuleExternalDowngrade:      "External link redirects from https to http",
+	RuleExternalRedirectsLimit: "External link exceeded RedirectLimit",
+	RuleMailtoEmpty:            "mailto link has no address",
+	RuleMailtoInvalid:          "mailto link has an invalid address",
+	RuleTelEmpty:               "tel link has no number",
+
+	RuleImgAltMissing:    "Image has no alt attribute",
+	RuleImgAltEmpty:      "Image has an empty alt attribute",
+	RuleImgAltWhitespace: "Image alt text contains only whitespace",
+	RuleImgSrcMissing:    "Image has no src attribute",
+	RuleImgSrcEmpty:      "Image has an empty src attribute",
+	RuleImgUsemapInvalid: "Image usemap isn't a hash",
+	RuleImgUsemapEmpty:   "Image usemap is empty",
+	RuleImgUsemapNested:  "Image with usemap inside an <a> or <button>",
+
+	RuleSrcsetEmpty:        "srcset has no image candidates",
+	RuleSrcsetInvalid:      "srcset candidate has a malformed descriptor",
+	RuleSrcsetMixed:        "srcset mixes width descriptors with density descriptors",
+	RuleSrcsetSizesMissing: "srcset has width descriptors but no sizes attribute",
+
+	RuleScriptSrcEmpty:       "Script src attribute is present but empty",
+	RuleScriptContentMissing: "Script has no content and no src attribute",
+
+	RuleMetaRefreshQuoted:  "Meta refresh url starts with a quote",
+	RuleMetaRefreshBlank:   "Meta refresh content attribute is blank",
+	RuleMetaRefreshInvalid: "Meta refresh content attribute is invalid",
+	RuleMetaRefreshMissing: "Meta refresh has no content attribute",
+
+	RuleOpenGraphMissing:     "Document lacks a required OpenGraph property",
+	RuleOpenGraphEmpty:       "OpenGraph or Twitter card property has no content",
+	RuleOpenGraphNotAbsolute: "OpenGraph or Twitter card URL isn't absolute",
+
+	RuleHeadTitleMissing:       "Document has no <title>",
+	RuleHeadTitleEmpty:         "Document's <title> is empty",
+	RuleHeadTitleMultiple:      "Document has more than one <title>",
+	RuleHeadDescriptionMissing: "Document has no meta description",
+	RuleHeadDescriptionLength:  "Meta description is shorter or longer than allowed",
+	RuleHeadCanonicalMultiple:  "Document has more than one canonical link",
+	RuleHeadCharsetMissing:     "Document doesn't declare its character encoding",
+	RuleHeadCharsetLate:        "Character encoding isn't declared within the first 1024 bytes",
+	RuleHeadLangMissing:        "<html> has no lang attribute",
+
+	RuleDuplicateTitle:       "Documents share the same <title>",
+	RuleDuplicateDescription: "Documents share the same meta description",
+	RuleDuplicateCanonical:   "Documents share the same canonical URL",
+
+	RuleHreflangInvalid:        "hreflang isn't a BCP 47 language tag or x-default",
+	RuleHreflangDefaultMissing: "Set of hreflang alternates has no x-default",
+	RuleHreflangNotReciprocal:  "hreflang alternate doesn't link back to the document",
+	RuleHreflangCanonicalised:  "hreflang alternate's canonical link points to another document",
+
+	RuleGraphOrphan:  "Document isn't reachable by links from the site root or an entry point",
+	RuleGraphTooDeep: "Document is more clicks from the site root than MaxClickDepth",
+
+	RuleFaviconMissing: "Document has no favicon",
+}
+
+// RuleDescription : Description of the rule with the given code, empty if the
+// code isn't known.
+func RuleDescription(rule string) string {
+	return ruleDescriptions[rule]
+}
+
+// RuleCodes : Codes of all known rules, sorted.
+func RuleCodes() []string {
+	codes := make([]string, 0, len(ruleDescriptions))
+	for code := range ruleDescriptions {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
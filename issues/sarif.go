@@ -0,0 +1,180 @@
+package issues
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/wjdp/htmltest/output"
+)
+
+// SARIF version written and the schema describing it
+const (
+	sarifVersion string = "2.1.0"
+	sarifSchema  string = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifLog : Layout of the report written by WriteSARIF, only the parts of
+// SARIF we fill in.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID        string             `json:"ruleId"`
+	RuleIndex     int                `json:"ruleIndex"`
+	Level         string             `json:"level"`
+	Message       sarifMessage       `json:"message"`
+	Locations     []sarifLocation    `json:"locations,omitempty"`
+	BaselineState string             `json:"baselineState,omitempty"`
+	Suppressions  []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// SARIF level for an issue level
+func sarifLevel(level int) string {
+	switch level {
+	case LevelError:
+		return "error"
+	case LevelWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+// Result for the issue, ruleIndex is the index of the issue's rule in the
+// driver's rules.
+func (issue *Issue) sarifResult(ruleIndex int) sarifResult {
+	result := sarifResult{
+		RuleID:    issue.Rule,
+		RuleIndex: ruleIndex,
+		Level:     sarifLevel(issue.Level),
+		Message:   sarifMessage{Text: issue.Message},
+	}
+	if issue.Reference != nil {
+		result.Message.Text += " --> " + issue.Reference.Path
+	}
+	if issue.store != nil && issue.store.baseline != nil {
+		// Compared against a baseline, say whether the issue is known
+		result.BaselineState = "new"
+		if issue.Baselined {
+			result.BaselineState = "unchanged"
+		}
+	}
+	if issue.Suppressed {
+		result.Suppressions = []sarifSuppression{{Kind: "inSource"}}
+	}
+
+	doc := issue.Document
+	if doc == nil && issue.Reference != nil {
+		doc = issue.Reference.Document
+	}
+	if doc == nil {
+		return result
+	}
+	location := sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(doc.FilePath)},
+		},
+	}
+	if issue.Reference != nil && issue.Reference.Line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{
+			StartLine:   issue.Reference.Line,
+			StartColumn: issue.Reference.Column,
+		}
+	}
+	result.Locations = []sarifLocation{location}
+	return result
+}
+
+// WriteSARIF : Write a SARIF 2.1.0 report of the issue store to the given
+// path, for code scanning tools. Issues are filtered by logLevel given in
+// NewIssueStore, those without a rule are left out. version is the version of
+// htmltest making the report.
+func (iS *IssueStore) WriteSARIF(path string, version string) {
+	driver := sarifDriver{
+		Name:           "htmltest",
+		InformationURI: "https://github.com/wjdp/htmltest",
+		Version:        version,
+		Rules:          make([]sarifRule, 0),
+	}
+	ruleIndexes := make(map[string]int)
+	for i, code := range RuleCodes() {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:               code,
+			ShortDescription: sarifMessage{Text: RuleDescription(code)},
+		})
+		ruleIndexes[code] = i
+	}
+
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: make([]sarifResult, 0)}
+	for _, issue := range iS.Issues(iS.logLevel) {
+		if ruleIndex, ok := ruleIndexes[issue.Rule]; ok {
+			run.Results = append(run.Results, issue.sarifResult(ruleIndex))
+		}
+	}
+
+	report := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+
+	os.MkdirAll(filepath.Dir(path), 0777)
+	f, err := os.Create(path)
+	output.CheckErrorPanic(err)
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(&report)
+	output.CheckErrorPanic(err)
+}
@@ -0,0 +1,76 @@
+package issues
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/daviddengcn/go-assert"
+	"github.com/wjdp/htmltest/htmldoc"
+)
+
+func TestIssueStoreWriteSARIF(t *testing.T) {
+	// passes for results with rule ids and file locations
+	SARIFFILE := "issue-store-test.sarif"
+	iS := NewIssueStore(LevelWarning, false)
+	doc := htmldoc.Document{
+		FilePath: "public/dir/page.html",
+		SitePath: "dir/page.html",
+	}
+	ref := htmldoc.Reference{
+		Document: &doc,
+		Path:     "missing.html",
+		Line:     12,
+		Column:   5,
+	}
+	iS.AddIssue(Issue{
+		Level:     LevelError,
+		Message:   "target does not exist",
+		Rule:      RuleLinkTargetMissing,
+		Reference: &ref,
+	})
+	iS.AddIssue(Issue{
+		Level:    LevelWarning,
+		Message:  "missing doctype",
+		Rule:     RuleDoctypeMissing,
+		Document: &doc,
+	})
+	iS.AddIssue(Issue{Level: LevelWarning, Message: "no rule", Document: &doc})
+	iS.AddIssue(Issue{Level: LevelInfo, Message: "hitting", Rule: RuleExternalStatus, Reference: &ref})
+
+	iS.WriteSARIF(SARIFFILE, "1.0.0")
+	sarifBytes, err := ioutil.ReadFile(SARIFFILE)
+	assert.Equals(t, "file error", err, nil)
+
+	var report sarifLog
+	err = json.Unmarshal(sarifBytes, &report)
+	assert.Equals(t, "json error", err, nil)
+	assert.Equals(t, "version", report.Version, "2.1.0")
+	driver := report.Runs[0].Tool.Driver
+	assert.Equals(t, "rule count", len(driver.Rules), len(RuleCodes()))
+
+	results := report.Runs[0].Results
+	assert.Equals(t, "result count", len(results), 2)
+	assert.Equals(t, "rule id", results[0].RuleID, RuleLinkTargetMissing)
+	assert.Equals(t, "rule index", driver.Rules[results[0].RuleIndex].ID, RuleLinkTargetMissing)
+	assert.Equals(t, "level", results[0].Level, "error")
+	assert.Equals(t, "message", results[0].Message.Text, "target does not exist --> missing.html")
+	location := results[0].Locations[0].PhysicalLocation
+	assert.Equals(t, "uri", location.ArtifactLocation.URI, "public/dir/page.html")
+	assert.Equals(t, "region", *location.Region, sarifRegion{StartLine: 12, StartColumn: 5})
+
+	assert.Equals(t, "document level", results[1].Level, "warning")
+	assert.Equals(t, "document region", results[1].Locations[0].PhysicalLocation.Region, (*sarifRegion)(nil))
+
+	removeErr := os.Remove(SARIFFILE)
+	assert.Equals(t, "file error", removeErr, nil)
+}
+
+func TestRuleDescriptions(t *testing.T) {
+	// every rule has a description
+	for _, code := range RuleCodes() {
+		assert.NotEquals(t, code+" description", RuleDescription(code), "")
+	}
+	assert.Equals(t, "unknown rule", RuleDescription("nope/nope"), "")
+}
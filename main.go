@@ -36,12 +36,18 @@ Usage:
 Options:
   <path>                       Path to directory or file to test, if omitted we
                                attempt to read from .htmltest.yml.
+  -b FILE, --baseline FILE     Baseline of known errors, these don't fail the
+                               test. Fixed ones are listed.
   -c FILE, --conf FILE         Custom path to config file.
+  --graph FILE                 Write the site's link graph to FILE, as Graphviz
+                               DOT if it ends .dot or .gv, otherwise JSON.
   -h, --help                   Show this text.
   -l LEVEL, --log-level LEVEL  Logging level, 0-3: debug, info, warning, error.
   -s, --skip-external          Skip external link checks, may shorten execution
                                time considerably.
   -v, --version                Show version and build time.
+  --write-baseline             Write the errors found to the --baseline file
+                               rather than failing on them.
 `
 	versionText := "htmltest " + version
 	arguments, _ := docopt.Parse(usage, nil, true, versionText, false)
@@ -147,6 +153,21 @@ func augmentWithCLIArgs(options optsMap, arguments map[string]interface{}) {
 		}
 	}
 
+	if arguments["--baseline"] != nil {
+		options["BaselineFile"] = arguments["--baseline"].(string)
+	}
+
+	if arguments["--graph"] != nil {
+		options["GraphFile"] = arguments["--graph"].(string)
+	}
+
+	if arguments["--write-baseline"].(bool) {
+		if arguments["--baseline"] == nil && options["BaselineFile"] == nil {
+			output.AbortWith("--write-baseline needs a file, set with --baseline")
+		}
+		options["WriteBaseline"] = true
+	}
+
 	if arguments["--skip-external"].(bool) {
 		output.Warn("Skipping the checking of external links.")
 		options["CheckExternal"] = false
@@ -171,6 +192,19 @@ func run(options optsMap) int {
 	timeEnd := time.Now()
 	numErrors := hT.CountErrors()
 
+	if options["WriteBaseline"] == true {
+		color.Set(color.FgHiGreen)
+		fmt.Println(cmdSeparator)
+		fmt.Println("wrote", numErrors, "errors to baseline", options["BaselineFile"])
+		color.Unset()
+		return 0
+	}
+
+	printBaseline(hT)
+	if numSuppressed := hT.CountSuppressed(); numSuppressed > 0 {
+		fmt.Println(numSuppressed, "issues suppressed by comments")
+	}
+
 	if numErrors == 0 {
 		color.Set(color.FgHiGreen)
 		fmt.Println("✔✔✔ passed in", timeEnd.Sub(timeStart))
@@ -193,3 +227,20 @@ func run(options optsMap) int {
 	return 1
 
 }
+
+// Summarise errors matched by the baseline and any entries since fixed
+func printBaseline(hT *htmltest.HTMLTest) {
+	if numBaselined := hT.CountBaselined(); numBaselined > 0 {
+		fmt.Println(numBaselined, "known errors in baseline")
+	}
+	fixed := hT.BaselineFixed()
+	if len(fixed) == 0 {
+		return
+	}
+	color.Set(color.FgHiGreen)
+	fmt.Println(len(fixed), "baseline entries fixed, update it with --write-baseline:")
+	for _, entry := range fixed {
+		fmt.Printf("  %s --- %s --> %s\n", entry.Rule, entry.Document, entry.Reference)
+	}
+	color.Unset()
+}
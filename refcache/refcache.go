@@ -3,6 +3,7 @@ package refcache
 
 import (
 	"encoding/json"
+	"net/url"
 	"os"
 	"path"
 	"sync"
@@ -11,11 +12,22 @@ import (
 	"github.com/wjdp/htmltest/output"
 )
 
+// Outcomes of an external check, results of each can be kept for a different
+// length of time.
+const (
+	OutcomeSuccess      = "success"
+	OutcomeClientError  = "client-error"
+	OutcomeServerError  = "server-error"
+	OutcomeNetworkError = "network-error"
+)
+
 // RefCache struct : store of cached references.
 type RefCache struct {
-	refStore     map[string]CachedRef
-	rwMutex      *sync.RWMutex
-	cacheExpires time.Duration
+	refStore       map[string]CachedRef
+	hostMethods    map[string]string // "HEAD" or "GET" per host once HEAD has been tried, built from refStore
+	rwMutex        *sync.RWMutex
+	cacheExpires   time.Duration
+	outcomeExpires map[string]time.Duration
 }
 
 // NewRefCache : Create a cached reference.
@@ -24,14 +36,31 @@ func NewRefCache(storePath string, cacheExpiresStr string) *RefCache {
 	_ = storePath
 	rS.rwMutex = &sync.RWMutex{}
 	rS.cacheExpires, _ = time.ParseDuration(cacheExpiresStr)
+	rS.outcomeExpires = make(map[string]time.Duration)
 
 	if !rS.ReadStore(storePath) {
 		rS.refStore = make(map[string]CachedRef)
+		rS.hostMethods = make(map[string]string)
 	}
 
 	return rS
 }
 
+// SetExpires : Set how long results with the given outcome are kept. Outcomes
+// without their own setting, including results saved without an outcome, use
+// the expiry given to NewRefCache.
+func (rS *RefCache) SetExpires(outcome string, expiresStr string) {
+	rS.outcomeExpires[outcome], _ = time.ParseDuration(expiresStr)
+}
+
+// How long results with the given outcome are kept
+func (rS *RefCache) expires(outcome string) time.Duration {
+	if expires, ok := rS.outcomeExpires[outcome]; ok {
+		return expires
+	}
+	return rS.cacheExpires
+}
+
 // ReadStore : Read a saved store from storePath.
 func (rS *RefCache) ReadStore(storePath string) bool {
 	// Read in RefCache
@@ -49,6 +78,10 @@ func (rS *RefCache) ReadStore(storePath string) bool {
 	output.CheckErrorPanic(err)
 
 	rS.refStore = refStore
+	rS.hostMethods = make(map[string]string)
+	for urlStr, cR := range refStore {
+		rS.saveHostMethod(urlStr, cR)
+	}
 	return true
 }
 
@@ -66,9 +99,24 @@ func (rS *RefCache) WriteStore(storePath string) {
 
 // CachedRef struct : Single cached result
 type CachedRef struct {
-	StatusCode int
-	LastSeen   time.Time
-	// Body byte[] // For when we do hash checking on external documents
+	StatusCode  int
+	LastSeen    time.Time
+	Hashes      []string   // id/names found in the page, nil when the body wasn't fetched
+	Method      string     // HTTP method that gave this result
+	HeadRefused bool       // A HEAD request was tried first and refused
+	Redirects   []Redirect // Redirects followed to reach the result, in order
+	Outcome     string     // One of the Outcome consts, empty when saved by an older version
+	ErrorClass  string     // Kind of network error, such as "dns" or "timeout", empty if there was a response
+	Error       string     // Network error message
+	FinalURL    string     // URL the final response came from
+	ContentType string     // Content-Type of the final response
+	Attempted   time.Time  // When the request was started
+}
+
+// Redirect struct : One hop of the redirect chain followed to get a result
+type Redirect struct {
+	StatusCode int    // Status code of the redirect response
+	URL        string // Location redirected to
 }
 
 // Get a cached result, thread safe.
@@ -78,7 +126,7 @@ func (rS *RefCache) Get(urlStr string) (*CachedRef, bool) {
 	rS.rwMutex.RUnlock()
 	if ok {
 		// In cache, check if cache has expired
-		if time.Now().Before(val.LastSeen.Add(rS.cacheExpires)) {
+		if time.Now().Before(val.LastSeen.Add(rS.expires(val.Outcome))) {
 			// All ok!
 			return &val, true
 		}
@@ -90,11 +138,39 @@ func (rS *RefCache) Get(urlStr string) (*CachedRef, bool) {
 
 // Save a result to the cache, thread safe.
 func (rS *RefCache) Save(urlStr string, statusCode int) {
-	cR := CachedRef{
-		StatusCode: statusCode,
-		LastSeen:   time.Now(),
-	}
+	rS.SaveRef(urlStr, CachedRef{StatusCode: statusCode})
+}
+
+// SaveRef : Save a full result to the cache, LastSeen is set to now. Thread
+// safe.
+func (rS *RefCache) SaveRef(urlStr string, cR CachedRef) {
+	cR.LastSeen = time.Now()
 	rS.rwMutex.Lock()
 	rS.refStore[urlStr] = cR
+	rS.saveHostMethod(urlStr, cR)
 	rS.rwMutex.Unlock()
 }
+
+// Record the outcome of trying HEAD against the host of urlStr, caller must
+// hold the write lock. GET is sticky, once a host has refused HEAD we don't go
+// back to it.
+func (rS *RefCache) saveHostMethod(urlStr string, cR CachedRef) {
+	u, err := url.Parse(urlStr)
+	if err != nil || rS.hostMethods[u.Host] == "GET" {
+		return
+	}
+	if cR.HeadRefused {
+		rS.hostMethods[u.Host] = "GET"
+	} else if cR.Method == "HEAD" {
+		rS.hostMethods[u.Host] = "HEAD"
+	}
+}
+
+// HostMethod : The HTTP method that works for host, known once a HEAD request
+// has been tried against it. "GET" if the host refused HEAD. Thread safe.
+func (rS *RefCache) HostMethod(host string) (string, bool) {
+	rS.rwMutex.RLock()
+	method, ok := rS.hostMethods[host]
+	rS.rwMutex.RUnlock()
+	return method, ok
+}
@@ -42,6 +42,45 @@ func TestRefCacheWriteRead(t *testing.T) {
 	assert.Equals(t, "url status in cache", cRY.StatusCode, 200)
 }
 
+func TestRefCacheWriteReadHashes(t *testing.T) {
+	// hashes survive a write and read, an empty set is distinct from none
+	rS1 := NewRefCache("does-not-exist", "2s")
+	rS1.SaveRef("http://example.com/a.html", CachedRef{StatusCode: 200, Hashes: []string{"top"}})
+	rS1.SaveRef("http://example.com/b.html", CachedRef{StatusCode: 200, Hashes: []string{}})
+	rS1.Save("http://example.com/c.html", 200)
+	STOREPATH := ".htmltest/refcache-test-writereadhashes.json"
+	rS1.WriteStore(STOREPATH)
+	rS2 := NewRefCache(STOREPATH, "2s")
+	cRA, _ := rS2.Get("http://example.com/a.html")
+	cRB, _ := rS2.Get("http://example.com/b.html")
+	cRC, _ := rS2.Get("http://example.com/c.html")
+	assert.StringEquals(t, "hashes in cache", cRA.Hashes, []string{"top"})
+	assert.IsTrue(t, "empty hashes in cache", cRB.Hashes != nil)
+	assert.IsTrue(t, "no hashes in cache", cRC.Hashes == nil)
+}
+
+func TestRefCacheHostMethod(t *testing.T) {
+	// the method that worked is remembered per host, and survives a write and read
+	rS1 := NewRefCache("does-not-exist", "2s")
+	rS1.SaveRef("http://example.com/a.html", CachedRef{StatusCode: 200, Method: "HEAD"})
+	rS1.SaveRef("http://example.com/b.html", CachedRef{StatusCode: 200, Method: "GET", HeadRefused: true})
+	rS1.SaveRef("http://example.com/c.html", CachedRef{StatusCode: 200, Method: "HEAD"})
+	rS1.SaveRef("http://example.org/", CachedRef{StatusCode: 200, Method: "HEAD"})
+	rS1.SaveRef("http://example.net/", CachedRef{StatusCode: 200, Method: "GET"})
+	method, ok := rS1.HostMethod("example.com")
+	assert.IsTrue(t, "host method known", ok)
+	assert.Equals(t, "host method", method, "GET")
+	_, ok = rS1.HostMethod("example.net")
+	assert.IsFalse(t, "host method unknown", ok)
+
+	STOREPATH := ".htmltest/refcache-test-hostmethod.json"
+	rS1.WriteStore(STOREPATH)
+	rS2 := NewRefCache(STOREPATH, "2s")
+	method, ok = rS2.HostMethod("example.org")
+	assert.IsTrue(t, "host method known", ok)
+	assert.Equals(t, "host method", method, "HEAD")
+}
+
 func TestRefCacheExpiry(t *testing.T) {
 	// does the cache invalidate?
 	rS := NewRefCache("does-not-exist", "1s")
@@ -54,3 +93,27 @@ func TestRefCacheExpiry(t *testing.T) {
 	_, okN := rS.Get(URLSTR)
 	assert.IsFalse(t, "cache invalid", okN)
 }
+
+func TestRefCacheOutcomeExpiry(t *testing.T) {
+	// each outcome is kept for its own time
+	rS := NewRefCache("does-not-exist", "1h")
+	rS.SetExpires(OutcomeClientError, "0s")
+	rS.SetExpires(OutcomeNetworkError, "1h")
+	rS.SaveRef("http://example.com/ok", CachedRef{StatusCode: 200, Outcome: OutcomeSuccess})
+	rS.SaveRef("http://example.com/missing", CachedRef{StatusCode: 404, Outcome: OutcomeClientError})
+	rS.SaveRef("http://dead.example.com/", CachedRef{
+		Outcome:    OutcomeNetworkError,
+		ErrorClass: "dns",
+		Error:      "no such host",
+	})
+	rS.SaveRef("http://example.com/error", CachedRef{StatusCode: 500, Outcome: OutcomeServerError})
+	_, ok := rS.Get("http://example.com/ok")
+	assert.IsTrue(t, "success cached", ok)
+	_, ok = rS.Get("http://example.com/missing")
+	assert.IsFalse(t, "client error expired", ok)
+	cR, ok := rS.Get("http://dead.example.com/")
+	assert.IsTrue(t, "network error cached", ok)
+	assert.Equals(t, "network error class", cR.ErrorClass, "dns")
+	_, ok = rS.Get("http://example.com/error")
+	assert.IsTrue(t, "server error uses default expiry", ok)
+}